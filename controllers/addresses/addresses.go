@@ -3,10 +3,12 @@
 package addresses
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/paybyphone/phpipam-sdk-go/phpipam"
 	"github.com/paybyphone/phpipam-sdk-go/phpipam/client"
+	"github.com/paybyphone/phpipam-sdk-go/phpipam/request"
 	"github.com/paybyphone/phpipam-sdk-go/phpipam/session"
 )
 
@@ -87,13 +89,85 @@ func NewController(sess *session.Session) *Controller {
 
 // CreateAddress creates an address by sending a POST request.
 func (c *Controller) CreateAddress(in Address) (message string, err error) {
-	err = c.SendRequest("POST", "/addresses/", &in, &message)
+	return c.CreateAddressWithContext(context.Background(), in)
+}
+
+// CreateAddressWithContext is the same as CreateAddress, but takes a
+// context.Context.
+func (c *Controller) CreateAddressWithContext(ctx context.Context, in Address) (message string, err error) {
+	err = c.SendRequestWithContext(ctx, "POST", "/addresses/", &in, &message)
+	return
+}
+
+// CreateAddressAndGetID creates an address by sending a POST request, and
+// returns the ID of the new address.
+func (c *Controller) CreateAddressAndGetID(in Address) (id int, err error) {
+	return c.CreateAddressAndGetIDWithContext(context.Background(), in)
+}
+
+// CreateAddressAndGetIDWithContext is the same as CreateAddressAndGetID, but
+// takes a context.Context.
+func (c *Controller) CreateAddressAndGetIDWithContext(ctx context.Context, in Address) (id int, err error) {
+	id, err = c.Client.CreateResourceWithContext(ctx, "/addresses/", &in)
+	return
+}
+
+// CreateAddressAndGet creates an address by sending a POST request, and
+// returns the new address as read back from the API.
+func (c *Controller) CreateAddressAndGet(in Address) (out Address, err error) {
+	return c.CreateAddressAndGetWithContext(context.Background(), in)
+}
+
+// CreateAddressAndGetWithContext is the same as CreateAddressAndGet, but takes
+// a context.Context.
+func (c *Controller) CreateAddressAndGetWithContext(ctx context.Context, in Address) (out Address, err error) {
+	var id int
+	id, err = c.CreateAddressAndGetIDWithContext(ctx, in)
+	if err != nil {
+		return
+	}
+	out, err = c.GetAddressByIDWithContext(ctx, id)
+	return
+}
+
+// CreateFirstFreeAddress creates an address using the first free IP address
+// in the subnet with the supplied ID by sending a POST request, and returns
+// the allocated IP address and the ID of the new address.
+//
+// Unlike subnets.Controller.GetFirstFreeAddress, the address is chosen and
+// reserved by PHPIPAM in a single request, so concurrent callers are never
+// handed the same address. Fields set in in, such as the hostname, are set on
+// the new address. The IP address and subnet ID should be left blank.
+func (c *Controller) CreateFirstFreeAddress(subnetID int, in Address) (ip string, id int, err error) {
+	return c.CreateFirstFreeAddressWithContext(context.Background(), subnetID, in)
+}
+
+// CreateFirstFreeAddressWithContext is the same as CreateFirstFreeAddress, but
+// takes a context.Context.
+func (c *Controller) CreateFirstFreeAddressWithContext(ctx context.Context, subnetID int, in Address) (ip string, id int, err error) {
+	uri := fmt.Sprintf("/addresses/first_free/%d/", subnetID)
+	var resp request.APIResponse
+	resp, err = c.SendRequestEnvelopeWithContext(ctx, "POST", uri, &in, &ip)
+	if err != nil {
+		return
+	}
+	if resp.ID == 0 || ip == "" {
+		err = fmt.Errorf("API did not return the IP address and ID of the resource created at %s", uri)
+		return
+	}
+	id = resp.ID
 	return
 }
 
 // GetAddressByID GETs an address via its ID.
 func (c *Controller) GetAddressByID(id int) (out Address, err error) {
-	err = c.SendRequest("GET", fmt.Sprintf("/addresses/%d/", id), &struct{}{}, &out)
+	return c.GetAddressByIDWithContext(context.Background(), id)
+}
+
+// GetAddressByIDWithContext is the same as GetAddressByID, but takes a
+// context.Context.
+func (c *Controller) GetAddressByIDWithContext(ctx context.Context, id int) (out Address, err error) {
+	err = c.SendRequestWithContext(ctx, "GET", fmt.Sprintf("/addresses/%d/", id), &struct{}{}, &out)
 	return
 }
 
@@ -102,45 +176,81 @@ func (c *Controller) GetAddressByID(id int) (out Address, err error) {
 // According to the spec, this can return multiple addresses, however it's not
 // entirely clear how to perform a search that would yield multiple results.
 func (c *Controller) GetAddressesByIP(ipaddr string) (out []Address, err error) {
-	err = c.SendRequest("GET", fmt.Sprintf("/addresses/search/%s/", ipaddr), &struct{}{}, &out)
+	return c.GetAddressesByIPWithContext(context.Background(), ipaddr)
+}
+
+// GetAddressesByIPWithContext is the same as GetAddressesByIP, but takes a
+// context.Context.
+func (c *Controller) GetAddressesByIPWithContext(ctx context.Context, ipaddr string) (out []Address, err error) {
+	err = c.SendRequestWithContext(ctx, "GET", fmt.Sprintf("/addresses/search/%s/", ipaddr), &struct{}{}, &out)
 	return
 }
 
 // GetAddressCustomFieldsSchema GETs the custom fields for the addresses controller via
 // client.GetCustomFieldsSchema.
 func (c *Controller) GetAddressCustomFieldsSchema() (out map[string]phpipam.CustomField, err error) {
-	out, err = c.Client.GetCustomFieldsSchema("addresses")
+	return c.GetAddressCustomFieldsSchemaWithContext(context.Background())
+}
+
+// GetAddressCustomFieldsSchemaWithContext is the same as
+// GetAddressCustomFieldsSchema, but takes a context.Context.
+func (c *Controller) GetAddressCustomFieldsSchemaWithContext(ctx context.Context) (out map[string]phpipam.CustomField, err error) {
+	out, err = c.Client.GetCustomFieldsSchemaWithContext(ctx, "addresses")
 	return
 }
 
 // GetAddressCustomFields GETs the custom fields for a subnet via
 // client.GetCustomFields.
 func (c *Controller) GetAddressCustomFields(id int) (out map[string]interface{}, err error) {
-	out, err = c.Client.GetCustomFields(id, "addresses")
+	return c.GetAddressCustomFieldsWithContext(context.Background(), id)
+}
+
+// GetAddressCustomFieldsWithContext is the same as GetAddressCustomFields, but
+// takes a context.Context.
+func (c *Controller) GetAddressCustomFieldsWithContext(ctx context.Context, id int) (out map[string]interface{}, err error) {
+	out, err = c.Client.GetCustomFieldsWithContext(ctx, id, "addresses")
 	return
 }
 
 // UpdateAddress updates an address by sending a PATCH request.
 func (c *Controller) UpdateAddress(in Address) (message string, err error) {
-	err = c.SendRequest("PATCH", "/addresses/", &in, &message)
+	return c.UpdateAddressWithContext(context.Background(), in)
+}
+
+// UpdateAddressWithContext is the same as UpdateAddress, but takes a
+// context.Context.
+func (c *Controller) UpdateAddressWithContext(ctx context.Context, in Address) (message string, err error) {
+	err = c.SendRequestWithContext(ctx, "PATCH", "/addresses/", &in, &message)
 	return
 }
 
 // UpdateAddressCustomFields PATCHes the subnet's custom fields via
 // client.UpdateCustomFields.
 func (c *Controller) UpdateAddressCustomFields(id int, in map[string]interface{}) (message string, err error) {
-	message, err = c.Client.UpdateCustomFields(id, in, "addresses")
+	return c.UpdateAddressCustomFieldsWithContext(context.Background(), id, in)
+}
+
+// UpdateAddressCustomFieldsWithContext is the same as
+// UpdateAddressCustomFields, but takes a context.Context.
+func (c *Controller) UpdateAddressCustomFieldsWithContext(ctx context.Context, id int, in map[string]interface{}) (message string, err error) {
+	message, err = c.Client.UpdateCustomFieldsWithContext(ctx, id, in, "addresses")
 	return
 }
 
 // DeleteAddress deletes an address by ID. RemoveDNS can be set to true if you
 // want to have any related DNS records deleted as well.
 func (c *Controller) DeleteAddress(id int, RemoveDNS phpipam.BoolIntString) (message string, err error) {
+	return c.DeleteAddressWithContext(context.Background(), id, RemoveDNS)
+}
+
+// DeleteAddressWithContext is the same as DeleteAddress, but takes a
+// context.Context.
+func (c *Controller) DeleteAddressWithContext(ctx context.Context, id int, RemoveDNS phpipam.BoolIntString) (message string, err error) {
 	in := struct {
 		RemoveDNS phpipam.BoolIntString `json:"remove_dns,omitempty"`
 	}{
 		RemoveDNS: RemoveDNS,
 	}
-	err = c.SendRequest("DELETE", fmt.Sprintf("/addresses/%d/", id), &in, &message)
+	err = c.SendRequestWithContext(ctx, "DELETE", fmt.Sprintf("/addresses/%d/", id), &in, &message)
 	return
 }
@@ -1,6 +1,7 @@
 package addresses
 
 import (
+	"encoding/json"
 	"log"
 	"net/http"
 	"net/http/httptest"
@@ -190,6 +191,27 @@ const testDeleteAddressOutputJSON = `
 }
 `
 
+const testCreateAddressAndGetIDOutputJSON = `
+{
+  "code": 201,
+  "success": true,
+  "message": "Address created",
+  "id": "11",
+  "time": 0.012
+}
+`
+
+const testCreateFirstFreeAddressOutputJSON = `
+{
+  "code": 201,
+  "success": true,
+  "message": "Address created",
+  "id": "12",
+  "data": "10.10.1.5",
+  "time": 0.012
+}
+`
+
 func newHTTPTestServer(f func(w http.ResponseWriter, r *http.Request)) *httptest.Server {
 	ts := httptest.NewServer(http.HandlerFunc(f))
 	return ts
@@ -241,6 +263,84 @@ func TestCreateAddress(t *testing.T) {
 	}
 }
 
+func TestCreateAddressAndGetID(t *testing.T) {
+	ts := httpCreatedTestServer(testCreateAddressAndGetIDOutputJSON)
+	defer ts.Close()
+	sess := fullSessionConfig()
+	sess.Config.Endpoint = ts.URL
+	client := NewController(sess)
+
+	in := testCreateAddressInput
+	expected := 11
+	actual, err := client.CreateAddressAndGetID(in)
+	if err != nil {
+		t.Fatalf("Bad: %s", err)
+	}
+
+	if expected != actual {
+		t.Fatalf("Expected %#v, got %#v", expected, actual)
+	}
+}
+
+func TestCreateAddressAndGetIDMissingID(t *testing.T) {
+	ts := httpCreatedTestServer(testCreateAddressOutputJSON)
+	defer ts.Close()
+	sess := fullSessionConfig()
+	sess.Config.Endpoint = ts.URL
+	client := NewController(sess)
+
+	in := testCreateAddressInput
+	if _, err := client.CreateAddressAndGetID(in); err == nil {
+		t.Fatalf("Expected error, got none")
+	}
+}
+
+func TestCreateFirstFreeAddress(t *testing.T) {
+	var in Address
+	ts := newHTTPTestServer(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Content-Type", "application/json")
+		if r.Method != "POST" || r.URL.Path != "/0123456789abcdefgh/addresses/first_free/3/" {
+			http.Error(w, "not found", http.StatusNotFound)
+			return
+		}
+		if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		http.Error(w, testCreateFirstFreeAddressOutputJSON, http.StatusCreated)
+	})
+	defer ts.Close()
+	sess := fullSessionConfig()
+	sess.Config.Endpoint = ts.URL
+	client := NewController(sess)
+
+	ip, id, err := client.CreateFirstFreeAddress(3, Address{Hostname: "server3.cust1.local"})
+	if err != nil {
+		t.Fatalf("Bad: %s", err)
+	}
+	if ip != "10.10.1.5" {
+		t.Fatalf("Expected IP 10.10.1.5, got %q", ip)
+	}
+	if id != 12 {
+		t.Fatalf("Expected ID 12, got %d", id)
+	}
+	if in.Hostname != "server3.cust1.local" {
+		t.Fatalf("Expected hostname to be sent, got %#v", in)
+	}
+}
+
+func TestCreateFirstFreeAddressMissingID(t *testing.T) {
+	ts := httpCreatedTestServer(testCreateAddressOutputJSON)
+	defer ts.Close()
+	sess := fullSessionConfig()
+	sess.Config.Endpoint = ts.URL
+	client := NewController(sess)
+
+	if _, _, err := client.CreateFirstFreeAddress(3, Address{}); err == nil {
+		t.Fatalf("Expected error, got none")
+	}
+}
+
 func TestGetAddressByID(t *testing.T) {
 	ts := httpOKTestServer(testGetAddressByIDOutputJSON)
 	defer ts.Close()
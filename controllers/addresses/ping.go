@@ -0,0 +1,189 @@
+package addresses
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// PingStatus is the reachability of an address, as found by a ping check.
+type PingStatus int
+
+const (
+	// PingOffline is the status of an address that did not respond to a ping
+	// check.
+	PingOffline PingStatus = iota
+
+	// PingOnline is the status of an address that responded to a ping check.
+	PingOnline
+)
+
+// String implements fmt.Stringer for PingStatus.
+func (s PingStatus) String() string {
+	switch s {
+	case PingOffline:
+		return "offline"
+	case PingOnline:
+		return "online"
+	}
+	return fmt.Sprintf("PingStatus(%d)", int(s))
+}
+
+// PingResult is the result of a ping check of an address.
+type PingResult struct {
+	// Whether or not the address responded. This is PingOnline if ExitCode is
+	// 0.
+	Status PingStatus
+
+	// The scan type used for the check, as set in the PHPIPAM settings (i.e.
+	// "ping", "pear", "fping").
+	ScanType string
+
+	// The exit code of the check. 0 means the address is online.
+	ExitCode int
+
+	// A description of the exit code.
+	ResultCode string
+
+	// The message returned by the API.
+	Message string
+
+	// The round-trip time of the check, if reported by the API. The built-in
+	// PHPIPAM scan types do not report this, in which case it is zero.
+	Latency time.Duration
+}
+
+// UnmarshalJSON implements json.Unmarshaler for PingResult. The exit code and
+// latency can be returned as either strings or numbers, so these are parsed by
+// hand.
+func (r *PingResult) UnmarshalJSON(b []byte) error {
+	var in struct {
+		ScanType   string          `json:"scan_type"`
+		ExitCode   json.RawMessage `json:"exit_code"`
+		ResultCode string          `json:"result_code"`
+		Message    string          `json:"message"`
+		Latency    json.RawMessage `json:"latency"`
+	}
+	if err := json.Unmarshal(b, &in); err != nil {
+		return err
+	}
+	code, ok := parsePingNumber(in.ExitCode)
+	if !ok {
+		return fmt.Errorf("Invalid exit code %s in ping result", in.ExitCode)
+	}
+	*r = PingResult{
+		ScanType:   in.ScanType,
+		ExitCode:   int(code),
+		ResultCode: in.ResultCode,
+		Message:    in.Message,
+	}
+	if r.ExitCode == 0 {
+		r.Status = PingOnline
+	}
+	// Latency is reported in milliseconds.
+	if ms, ok := parsePingNumber(in.Latency); ok {
+		r.Latency = time.Duration(ms * float64(time.Millisecond))
+	}
+	return nil
+}
+
+// parsePingNumber parses a ping result value that is either a JSON number or
+// a string holding a number. false is returned for anything else, including
+// a missing value.
+func parsePingNumber(b json.RawMessage) (float64, bool) {
+	if len(b) == 0 {
+		return 0, false
+	}
+	var f float64
+	if err := json.Unmarshal(b, &f); err == nil {
+		return f, true
+	}
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return 0, false
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, false
+	}
+	return f, true
+}
+
+// PingAddress runs a ping check of an address, via a supplied address ID. The
+// check is run by the PHPIPAM server, using the scan type set in its settings.
+// If the address is online, PHPIPAM updates its LastSeen time as well.
+func (c *Controller) PingAddress(id int) (out PingResult, err error) {
+	return c.PingAddressWithContext(context.Background(), id)
+}
+
+// PingAddressWithContext is the same as PingAddress, but takes a
+// context.Context.
+func (c *Controller) PingAddressWithContext(ctx context.Context, id int) (out PingResult, err error) {
+	err = c.SendRequestWithContext(ctx, "GET", fmt.Sprintf("/addresses/%d/ping/", id), &struct{}{}, &out)
+	return
+}
+
+// PingAddresses runs ping checks of the addresses with the supplied IDs via
+// PingAddress, and returns the results keyed by address ID. Up to workers
+// checks are run at once. A workers value less than 1 is treated as 1.
+//
+// The first error stops any remaining checks and is returned, along with the
+// results of the checks that completed.
+func (c *Controller) PingAddresses(ids []int, workers int) (out map[int]PingResult, err error) {
+	return c.PingAddressesWithContext(context.Background(), ids, workers)
+}
+
+// PingAddressesWithContext is the same as PingAddresses, but takes a
+// context.Context.
+func (c *Controller) PingAddressesWithContext(ctx context.Context, ids []int, workers int) (out map[int]PingResult, err error) {
+	if workers < 1 {
+		workers = 1
+	}
+	if workers > len(ids) {
+		workers = len(ids)
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	out = make(map[int]PingResult, len(ids))
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	jobs := make(chan int)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for id := range jobs {
+				r, perr := c.PingAddressWithContext(ctx, id)
+				mu.Lock()
+				switch {
+				case perr == nil:
+					out[id] = r
+				case err == nil:
+					err = fmt.Errorf("Error pinging address %d: %w", id, perr)
+					cancel()
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+feed:
+	for _, id := range ids {
+		select {
+		case jobs <- id:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	if err == nil {
+		err = ctx.Err()
+	}
+	return
+}
@@ -0,0 +1,218 @@
+package addresses
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+var testPingAddressOutputExpected = PingResult{
+	Status:     PingOnline,
+	ScanType:   "ping",
+	ExitCode:   0,
+	ResultCode: "OK",
+	Message:    "Address online",
+}
+
+const testPingAddressOutputJSON = `
+{
+  "code": 200,
+  "success": true,
+  "data": {
+    "scan_type": "ping",
+    "exit_code": 0,
+    "result_code": "OK",
+    "message": "Address online"
+  }
+}
+`
+
+const testPingAddressOfflineOutputJSON = `
+{
+  "code": 200,
+  "success": true,
+  "data": {
+    "scan_type": "fping",
+    "exit_code": "1",
+    "result_code": "Address offline",
+    "message": "Address offline"
+  }
+}
+`
+
+func TestPingStatusString(t *testing.T) {
+	for s, expected := range map[PingStatus]string{
+		PingOffline:   "offline",
+		PingOnline:    "online",
+		PingStatus(5): "PingStatus(5)",
+	} {
+		if actual := s.String(); expected != actual {
+			t.Fatalf("Expected %s, got %s", expected, actual)
+		}
+	}
+}
+
+func TestPingResultUnmarshalJSON(t *testing.T) {
+	cases := map[string]PingResult{
+		`{"scan_type":"fping","exit_code":"1","result_code":"Address offline","message":"Address offline"}`: PingResult{
+			Status:     PingOffline,
+			ScanType:   "fping",
+			ExitCode:   1,
+			ResultCode: "Address offline",
+			Message:    "Address offline",
+		},
+		`{"scan_type":"ping","exit_code":"0","result_code":"OK","message":"Address online","latency":"1.5"}`: PingResult{
+			Status:     PingOnline,
+			ScanType:   "ping",
+			ExitCode:   0,
+			ResultCode: "OK",
+			Message:    "Address online",
+			Latency:    1500 * time.Microsecond,
+		},
+		`{"scan_type":"ping","exit_code":0,"latency":12}`: PingResult{
+			Status:   PingOnline,
+			ScanType: "ping",
+			Latency:  12 * time.Millisecond,
+		},
+	}
+	for in, expected := range cases {
+		var actual PingResult
+		if err := json.Unmarshal([]byte(in), &actual); err != nil {
+			t.Fatalf("%s: Bad: %s", in, err)
+		}
+		if !reflect.DeepEqual(expected, actual) {
+			t.Fatalf("%s: Expected %#v, got %#v", in, expected, actual)
+		}
+	}
+}
+
+func TestPingResultUnmarshalJSONInvalidExitCode(t *testing.T) {
+	for _, in := range []string{
+		`{"scan_type":"ping"}`,
+		`{"scan_type":"ping","exit_code":"x"}`,
+	} {
+		var r PingResult
+		if err := json.Unmarshal([]byte(in), &r); err == nil {
+			t.Fatalf("%s: Expected error, got none", in)
+		}
+	}
+}
+
+func TestPingAddress(t *testing.T) {
+	ts := httpOKTestServer(testPingAddressOutputJSON)
+	defer ts.Close()
+	sess := fullSessionConfig()
+	sess.Config.Endpoint = ts.URL
+	client := NewController(sess)
+
+	expected := testPingAddressOutputExpected
+	actual, err := client.PingAddress(1)
+	if err != nil {
+		t.Fatalf("Bad: %s", err)
+	}
+	if !reflect.DeepEqual(expected, actual) {
+		t.Fatalf("Expected %#v, got %#v", expected, actual)
+	}
+}
+
+// httpPingTestServer returns a test server for PingAddresses. Odd address IDs
+// are online, even IDs are offline, and IDs above 100 are not found. The
+// highest number of checks seen in flight at once is stored in maxInFlight.
+func httpPingTestServer(maxInFlight *int) *httptest.Server {
+	var mu sync.Mutex
+	var inFlight int
+	return newHTTPTestServer(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		inFlight++
+		if inFlight > *maxInFlight {
+			*maxInFlight = inFlight
+		}
+		mu.Unlock()
+		defer func() {
+			mu.Lock()
+			inFlight--
+			mu.Unlock()
+		}()
+		// Hold the request open long enough for the other workers to start.
+		time.Sleep(10 * time.Millisecond)
+
+		w.Header().Add("Content-Type", "application/json")
+		var id int
+		path := strings.TrimPrefix(r.URL.Path, "/0123456789abcdefgh")
+		if _, err := fmt.Sscanf(path, "/addresses/%d/ping/", &id); err != nil || id > 100 {
+			http.Error(w, `{"code":404,"success":false,"message":"Address not found"}`, http.StatusNotFound)
+			return
+		}
+		if id%2 == 1 {
+			http.Error(w, testPingAddressOutputJSON, http.StatusOK)
+			return
+		}
+		http.Error(w, testPingAddressOfflineOutputJSON, http.StatusOK)
+	})
+}
+
+func TestPingAddresses(t *testing.T) {
+	var maxInFlight int
+	ts := httpPingTestServer(&maxInFlight)
+	defer ts.Close()
+	sess := fullSessionConfig()
+	sess.Config.Endpoint = ts.URL
+	client := NewController(sess)
+
+	ids := []int{1, 2, 3, 4, 5, 6, 7, 8}
+	out, err := client.PingAddresses(ids, 3)
+	if err != nil {
+		t.Fatalf("Bad: %s", err)
+	}
+	if len(out) != len(ids) {
+		t.Fatalf("Expected %d results, got %d", len(ids), len(out))
+	}
+	for _, id := range ids {
+		expected := PingOffline
+		if id%2 == 1 {
+			expected = PingOnline
+		}
+		if actual := out[id].Status; expected != actual {
+			t.Fatalf("Address %d: expected %s, got %s", id, expected, actual)
+		}
+	}
+	if maxInFlight > 3 {
+		t.Fatalf("Expected at most 3 checks in flight, got %d", maxInFlight)
+	}
+}
+
+func TestPingAddressesError(t *testing.T) {
+	var maxInFlight int
+	ts := httpPingTestServer(&maxInFlight)
+	defer ts.Close()
+	sess := fullSessionConfig()
+	sess.Config.Endpoint = ts.URL
+	client := NewController(sess)
+
+	_, err := client.PingAddresses([]int{1, 200, 3, 4, 5, 6, 7, 8}, 2)
+	if err == nil {
+		t.Fatalf("Expected error, got none")
+	}
+	if !strings.HasPrefix(err.Error(), "Error pinging address 200: ") {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+}
+
+func TestPingAddressesEmpty(t *testing.T) {
+	sess := fullSessionConfig()
+	client := NewController(sess)
+
+	out, err := client.PingAddresses(nil, 4)
+	if err != nil {
+		t.Fatalf("Bad: %s", err)
+	}
+	if len(out) != 0 {
+		t.Fatalf("Expected no results, got %#v", out)
+	}
+}
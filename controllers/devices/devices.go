@@ -0,0 +1,258 @@
+// Package devices provides types and methods for working with the devices
+// controller.
+package devices
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"github.com/paybyphone/phpipam-sdk-go/controllers/addresses"
+	"github.com/paybyphone/phpipam-sdk-go/controllers/subnets"
+	"github.com/paybyphone/phpipam-sdk-go/phpipam"
+	"github.com/paybyphone/phpipam-sdk-go/phpipam/client"
+	"github.com/paybyphone/phpipam-sdk-go/phpipam/session"
+)
+
+// Device represents a PHPIPAM device.
+type Device struct {
+	// The device ID.
+	ID int `json:"id,string,omitempty"`
+
+	// The device's hostname.
+	Hostname string `json:"hostname,omitempty"`
+
+	// The device's IP address.
+	IPAddress string `json:"ip,omitempty"`
+
+	// The ID of the device's type.
+	Type int `json:"type,string,omitempty"`
+
+	// A detailed description of the device.
+	Description string `json:"description,omitempty"`
+
+	// A semicolon-separated list of the IDs of the sections that this device
+	// belongs to (i.e. "1;2;3").
+	Sections string `json:"sections,omitempty"`
+
+	// The SNMP community string for the device.
+	SNMPCommunity string `json:"snmp_community,omitempty"`
+
+	// The SNMP version to use when querying the device. 0 disables SNMP.
+	SNMPVersion int `json:"snmp_version,string,omitempty"`
+
+	// The SNMP port to use when querying the device.
+	SNMPPort int `json:"snmp_port,string,omitempty"`
+
+	// The SNMP timeout, in milliseconds.
+	SNMPTimeout int `json:"snmp_timeout,string,omitempty"`
+
+	// A semicolon-separated list of the SNMP queries that are enabled for the
+	// device.
+	SNMPQueries string `json:"snmp_queries,omitempty"`
+
+	// The ID of the rack the device is mounted in.
+	Rack int `json:"rack,string,omitempty"`
+
+	// The starting unit of the device in its rack.
+	RackStart int `json:"rack_start,string,omitempty"`
+
+	// The size of the device in rack units.
+	RackSize int `json:"rack_size,string,omitempty"`
+
+	// The ID of the location of the device.
+	Location int `json:"location,string,omitempty"`
+
+	// The date of the last edit to this resource.
+	EditDate string `json:"editDate,omitempty"`
+
+	// A map[string]interface{} of custom fields to set on the resource. Note
+	// that this functionality requires PHPIPAM 1.3 or higher with the "Nest
+	// custom fields" flag set on the specific API integration. If this is not
+	// enabled, this map will be nil on GETs and POSTs and PATCHes with this
+	// field set will fail. Use the explicit custom field functions instead.
+	CustomFields map[string]interface{} `json:"custom_fields,omitempty"`
+}
+
+// Controller is the base client for the Devices controller.
+type Controller struct {
+	client.Client
+}
+
+// NewController returns a new instance of the client for the Devices controller.
+func NewController(sess *session.Session) *Controller {
+	c := &Controller{
+		Client: *client.NewClient(sess),
+	}
+	return c
+}
+
+// ListDevices lists all devices.
+func (c *Controller) ListDevices() (out []Device, err error) {
+	return c.ListDevicesWithContext(context.Background())
+}
+
+// ListDevicesWithContext is the same as ListDevices, but takes a
+// context.Context.
+func (c *Controller) ListDevicesWithContext(ctx context.Context) (out []Device, err error) {
+	err = c.SendRequestWithContext(ctx, "GET", "/devices/", &struct{}{}, &out)
+	return
+}
+
+// CreateDevice creates a device by sending a POST request.
+func (c *Controller) CreateDevice(in Device) (message string, err error) {
+	return c.CreateDeviceWithContext(context.Background(), in)
+}
+
+// CreateDeviceWithContext is the same as CreateDevice, but takes a
+// context.Context.
+func (c *Controller) CreateDeviceWithContext(ctx context.Context, in Device) (message string, err error) {
+	err = c.SendRequestWithContext(ctx, "POST", "/devices/", &in, &message)
+	return
+}
+
+// CreateDeviceAndGetID creates a device by sending a POST request, and
+// returns the ID of the new device.
+func (c *Controller) CreateDeviceAndGetID(in Device) (id int, err error) {
+	return c.CreateDeviceAndGetIDWithContext(context.Background(), in)
+}
+
+// CreateDeviceAndGetIDWithContext is the same as CreateDeviceAndGetID, but
+// takes a context.Context.
+func (c *Controller) CreateDeviceAndGetIDWithContext(ctx context.Context, in Device) (id int, err error) {
+	id, err = c.Client.CreateResourceWithContext(ctx, "/devices/", &in)
+	return
+}
+
+// CreateDeviceAndGet creates a device by sending a POST request, and returns
+// the new device as read back from the API.
+func (c *Controller) CreateDeviceAndGet(in Device) (out Device, err error) {
+	return c.CreateDeviceAndGetWithContext(context.Background(), in)
+}
+
+// CreateDeviceAndGetWithContext is the same as CreateDeviceAndGet, but takes a
+// context.Context.
+func (c *Controller) CreateDeviceAndGetWithContext(ctx context.Context, in Device) (out Device, err error) {
+	var id int
+	id, err = c.CreateDeviceAndGetIDWithContext(ctx, in)
+	if err != nil {
+		return
+	}
+	out, err = c.GetDeviceByIDWithContext(ctx, id)
+	return
+}
+
+// GetDeviceByID GETs a device via its ID.
+func (c *Controller) GetDeviceByID(id int) (out Device, err error) {
+	return c.GetDeviceByIDWithContext(context.Background(), id)
+}
+
+// GetDeviceByIDWithContext is the same as GetDeviceByID, but takes a
+// context.Context.
+func (c *Controller) GetDeviceByIDWithContext(ctx context.Context, id int) (out Device, err error) {
+	err = c.SendRequestWithContext(ctx, "GET", fmt.Sprintf("/devices/%d/", id), &struct{}{}, &out)
+	return
+}
+
+// SearchDevices searches for devices matching the supplied search term. The
+// search is performed across all device fields, such as the hostname, IP
+// address, and description.
+func (c *Controller) SearchDevices(term string) (out []Device, err error) {
+	return c.SearchDevicesWithContext(context.Background(), term)
+}
+
+// SearchDevicesWithContext is the same as SearchDevices, but takes a
+// context.Context.
+func (c *Controller) SearchDevicesWithContext(ctx context.Context, term string) (out []Device, err error) {
+	err = c.SendRequestWithContext(ctx, "GET", fmt.Sprintf("/devices/search/%s/", url.PathEscape(term)), &struct{}{}, &out)
+	return
+}
+
+// GetSubnetsForDevice GETs the subnets that are attached to a device, via a
+// supplied device ID.
+func (c *Controller) GetSubnetsForDevice(id int) (out []subnets.Subnet, err error) {
+	return c.GetSubnetsForDeviceWithContext(context.Background(), id)
+}
+
+// GetSubnetsForDeviceWithContext is the same as GetSubnetsForDevice, but takes
+// a context.Context.
+func (c *Controller) GetSubnetsForDeviceWithContext(ctx context.Context, id int) (out []subnets.Subnet, err error) {
+	err = c.SendRequestWithContext(ctx, "GET", fmt.Sprintf("/devices/%d/subnets/", id), &struct{}{}, &out)
+	return
+}
+
+// GetAddressesForDevice GETs the IP addresses that are attached to a device,
+// via a supplied device ID.
+func (c *Controller) GetAddressesForDevice(id int) (out []addresses.Address, err error) {
+	return c.GetAddressesForDeviceWithContext(context.Background(), id)
+}
+
+// GetAddressesForDeviceWithContext is the same as GetAddressesForDevice, but
+// takes a context.Context.
+func (c *Controller) GetAddressesForDeviceWithContext(ctx context.Context, id int) (out []addresses.Address, err error) {
+	err = c.SendRequestWithContext(ctx, "GET", fmt.Sprintf("/devices/%d/addresses/", id), &struct{}{}, &out)
+	return
+}
+
+// GetDeviceCustomFieldsSchema GETs the custom fields for the devices
+// controller via client.GetCustomFieldsSchema.
+func (c *Controller) GetDeviceCustomFieldsSchema() (out map[string]phpipam.CustomField, err error) {
+	return c.GetDeviceCustomFieldsSchemaWithContext(context.Background())
+}
+
+// GetDeviceCustomFieldsSchemaWithContext is the same as
+// GetDeviceCustomFieldsSchema, but takes a context.Context.
+func (c *Controller) GetDeviceCustomFieldsSchemaWithContext(ctx context.Context) (out map[string]phpipam.CustomField, err error) {
+	out, err = c.Client.GetCustomFieldsSchemaWithContext(ctx, "devices")
+	return
+}
+
+// GetDeviceCustomFields GETs the custom fields for a device via
+// client.GetCustomFields.
+func (c *Controller) GetDeviceCustomFields(id int) (out map[string]interface{}, err error) {
+	return c.GetDeviceCustomFieldsWithContext(context.Background(), id)
+}
+
+// GetDeviceCustomFieldsWithContext is the same as GetDeviceCustomFields, but
+// takes a context.Context.
+func (c *Controller) GetDeviceCustomFieldsWithContext(ctx context.Context, id int) (out map[string]interface{}, err error) {
+	out, err = c.Client.GetCustomFieldsWithContext(ctx, id, "devices")
+	return
+}
+
+// UpdateDevice updates a device by sending a PATCH request.
+func (c *Controller) UpdateDevice(in Device) (message string, err error) {
+	return c.UpdateDeviceWithContext(context.Background(), in)
+}
+
+// UpdateDeviceWithContext is the same as UpdateDevice, but takes a
+// context.Context.
+func (c *Controller) UpdateDeviceWithContext(ctx context.Context, in Device) (message string, err error) {
+	err = c.SendRequestWithContext(ctx, "PATCH", "/devices/", &in, &message)
+	return
+}
+
+// UpdateDeviceCustomFields PATCHes the device's custom fields via
+// client.UpdateCustomFields.
+func (c *Controller) UpdateDeviceCustomFields(id int, in map[string]interface{}) (message string, err error) {
+	return c.UpdateDeviceCustomFieldsWithContext(context.Background(), id, in)
+}
+
+// UpdateDeviceCustomFieldsWithContext is the same as UpdateDeviceCustomFields,
+// but takes a context.Context.
+func (c *Controller) UpdateDeviceCustomFieldsWithContext(ctx context.Context, id int, in map[string]interface{}) (message string, err error) {
+	message, err = c.Client.UpdateCustomFieldsWithContext(ctx, id, in, "devices")
+	return
+}
+
+// DeleteDevice deletes a device by its ID.
+func (c *Controller) DeleteDevice(id int) (message string, err error) {
+	return c.DeleteDeviceWithContext(context.Background(), id)
+}
+
+// DeleteDeviceWithContext is the same as DeleteDevice, but takes a
+// context.Context.
+func (c *Controller) DeleteDeviceWithContext(ctx context.Context, id int) (message string, err error) {
+	err = c.SendRequestWithContext(ctx, "DELETE", fmt.Sprintf("/devices/%d/", id), &struct{}{}, &message)
+	return
+}
@@ -0,0 +1,557 @@
+package devices
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/paybyphone/phpipam-sdk-go/controllers/addresses"
+	"github.com/paybyphone/phpipam-sdk-go/controllers/subnets"
+	"github.com/paybyphone/phpipam-sdk-go/phpipam"
+	"github.com/paybyphone/phpipam-sdk-go/phpipam/session"
+	"github.com/paybyphone/phpipam-sdk-go/testacc"
+)
+
+var testCreateDeviceInput = Device{
+	Hostname:    "switch1.cust1.local",
+	IPAddress:   "10.10.1.250",
+	Type:        1,
+	Description: "Core switch",
+	Sections:    "1;2",
+}
+
+const testCreateDeviceOutputExpected = `Device created`
+const testCreateDeviceOutputJSON = `
+{
+  "code": 201,
+  "success": true,
+  "data": "Device created"
+}
+`
+
+const testCreateDeviceAndGetIDOutputJSON = `
+{
+  "code": 201,
+  "success": true,
+  "message": "Device created",
+  "id": "4",
+  "time": 0.012
+}
+`
+
+var testGetDeviceByIDOutputExpected = Device{
+	ID:          4,
+	Hostname:    "switch1.cust1.local",
+	IPAddress:   "10.10.1.250",
+	Type:        1,
+	Description: "Core switch",
+	Sections:    "1;2",
+	SNMPVersion: 2,
+	SNMPPort:    161,
+	SNMPTimeout: 1000,
+	Location:    3,
+}
+
+const testGetDeviceByIDOutputJSON = `
+{
+  "code": 200,
+  "success": true,
+  "data": {
+    "id": "4",
+    "hostname": "switch1.cust1.local",
+    "ip": "10.10.1.250",
+    "type": "1",
+    "description": "Core switch",
+    "sections": "1;2",
+    "snmp_community": null,
+    "snmp_version": "2",
+    "snmp_port": "161",
+    "snmp_timeout": "1000",
+    "snmp_queries": null,
+    "rack": null,
+    "rack_start": null,
+    "rack_size": null,
+    "location": "3",
+    "editDate": null,
+    "links": [
+      {
+        "rel": "self",
+        "href": "/api/test/devices/4/"
+      }
+    ]
+  }
+}
+`
+
+var testListDevicesOutputExpected = []Device{
+	Device{
+		ID:        4,
+		Hostname:  "switch1.cust1.local",
+		IPAddress: "10.10.1.250",
+		Type:      1,
+		Sections:  "1;2",
+	},
+	Device{
+		ID:        5,
+		Hostname:  "switch2.cust1.local",
+		IPAddress: "10.10.1.251",
+		Type:      1,
+		Sections:  "1;2",
+	},
+}
+
+const testListDevicesOutputJSON = `
+{
+  "code": 200,
+  "success": true,
+  "data": [
+    {
+      "id": "4",
+      "hostname": "switch1.cust1.local",
+      "ip": "10.10.1.250",
+      "type": "1",
+      "description": null,
+      "sections": "1;2",
+      "editDate": null
+    },
+    {
+      "id": "5",
+      "hostname": "switch2.cust1.local",
+      "ip": "10.10.1.251",
+      "type": "1",
+      "description": null,
+      "sections": "1;2",
+      "editDate": null
+    }
+  ]
+}
+`
+
+var testGetSubnetsForDeviceOutputExpected = []subnets.Subnet{
+	subnets.Subnet{
+		ID:             3,
+		SubnetAddress:  "10.10.1.0",
+		Mask:           24,
+		SectionID:      1,
+		MasterSubnetID: 2,
+	},
+}
+
+const testGetSubnetsForDeviceOutputJSON = `
+{
+  "code": 200,
+  "success": true,
+  "data": [
+    {
+      "id": "3",
+      "subnet": "10.10.1.0",
+      "mask": "24",
+      "sectionId": "1",
+      "masterSubnetId": "2",
+      "device": "4"
+    }
+  ]
+}
+`
+
+var testGetAddressesForDeviceOutputExpected = []addresses.Address{
+	addresses.Address{
+		ID:          1,
+		SubnetID:    3,
+		IPAddress:   "10.10.1.3",
+		Description: "Server1",
+		DeviceID:    4,
+	},
+}
+
+const testGetAddressesForDeviceOutputJSON = `
+{
+  "code": 200,
+  "success": true,
+  "data": [
+    {
+      "id": "1",
+      "subnetId": "3",
+      "ip": "10.10.1.3",
+      "description": "Server1",
+      "deviceId": "4"
+    }
+  ]
+}
+`
+
+var testGetDeviceCustomFieldsSchemaExpected = map[string]phpipam.CustomField{
+	"CustomTestDevices": phpipam.CustomField{
+		Name:    "CustomTestDevices",
+		Type:    "varchar(255)",
+		Comment: "Test field for devices controller",
+		Null:    "YES",
+		Default: "",
+	},
+}
+
+const testGetDeviceCustomFieldsSchemaJSON = `
+{
+  "code": 200,
+  "success": true,
+  "data": {
+    "CustomTestDevices": {
+      "name": "CustomTestDevices",
+      "type": "varchar(255)",
+      "Comment": "Test field for devices controller",
+      "Null": "YES",
+      "Default": ""
+    }
+  }
+}
+`
+
+var testUpdateDeviceInput = Device{
+	ID:          4,
+	Description: "foobat",
+}
+
+const testUpdateDeviceOutputExpected = `Device updated`
+const testUpdateDeviceOutputJSON = `
+{
+  "code": 200,
+  "success": true,
+  "data": "Device updated"
+}
+`
+
+const testDeleteDeviceOutputExpected = `Device deleted`
+const testDeleteDeviceOutputJSON = `
+{
+  "code": 200,
+  "success": true,
+  "data": "Device deleted"
+}
+`
+
+func newHTTPTestServer(f func(w http.ResponseWriter, r *http.Request)) *httptest.Server {
+	ts := httptest.NewServer(http.HandlerFunc(f))
+	return ts
+}
+
+func httpOKTestServer(output string) *httptest.Server {
+	return newHTTPTestServer(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Content-Type", "application/json")
+		http.Error(w, output, http.StatusOK)
+	})
+}
+
+func httpCreatedTestServer(output string) *httptest.Server {
+	return newHTTPTestServer(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Content-Type", "application/json")
+		http.Error(w, output, http.StatusCreated)
+	})
+}
+
+func fullSessionConfig() *session.Session {
+	return &session.Session{
+		Config: phpipam.Config{
+			AppID:    "0123456789abcdefgh",
+			Password: "changeit",
+			Username: "nobody",
+		},
+		Token: session.Token{
+			String: "foobarbazboop",
+		},
+	}
+}
+
+func TestListDevices(t *testing.T) {
+	ts := httpOKTestServer(testListDevicesOutputJSON)
+	defer ts.Close()
+	sess := fullSessionConfig()
+	sess.Config.Endpoint = ts.URL
+	client := NewController(sess)
+
+	expected := testListDevicesOutputExpected
+	actual, err := client.ListDevices()
+	if err != nil {
+		t.Fatalf("Bad: %s", err)
+	}
+
+	if !reflect.DeepEqual(expected, actual) {
+		t.Fatalf("Expected %#v, got %#v", expected, actual)
+	}
+}
+
+func TestCreateDevice(t *testing.T) {
+	ts := httpCreatedTestServer(testCreateDeviceOutputJSON)
+	defer ts.Close()
+	sess := fullSessionConfig()
+	sess.Config.Endpoint = ts.URL
+	client := NewController(sess)
+
+	in := testCreateDeviceInput
+	expected := testCreateDeviceOutputExpected
+	actual, err := client.CreateDevice(in)
+	if err != nil {
+		t.Fatalf("Bad: %s", err)
+	}
+
+	if !reflect.DeepEqual(expected, actual) {
+		t.Fatalf("Expected %#v, got %#v", expected, actual)
+	}
+}
+
+func TestCreateDeviceAndGet(t *testing.T) {
+	ts := newHTTPTestServer(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Content-Type", "application/json")
+		switch {
+		case r.Method == "POST" && r.URL.Path == "/0123456789abcdefgh/devices/":
+			http.Error(w, testCreateDeviceAndGetIDOutputJSON, http.StatusCreated)
+		case r.Method == "GET" && r.URL.Path == "/0123456789abcdefgh/devices/4/":
+			http.Error(w, testGetDeviceByIDOutputJSON, http.StatusOK)
+		default:
+			http.Error(w, "not found", http.StatusNotFound)
+		}
+	})
+	defer ts.Close()
+	sess := fullSessionConfig()
+	sess.Config.Endpoint = ts.URL
+	client := NewController(sess)
+
+	in := testCreateDeviceInput
+	expected := testGetDeviceByIDOutputExpected
+	actual, err := client.CreateDeviceAndGet(in)
+	if err != nil {
+		t.Fatalf("Bad: %s", err)
+	}
+
+	if !reflect.DeepEqual(expected, actual) {
+		t.Fatalf("Expected %#v, got %#v", expected, actual)
+	}
+}
+
+func TestGetDeviceByID(t *testing.T) {
+	ts := httpOKTestServer(testGetDeviceByIDOutputJSON)
+	defer ts.Close()
+	sess := fullSessionConfig()
+	sess.Config.Endpoint = ts.URL
+	client := NewController(sess)
+
+	expected := testGetDeviceByIDOutputExpected
+	actual, err := client.GetDeviceByID(4)
+	if err != nil {
+		t.Fatalf("Bad: %s", err)
+	}
+
+	if !reflect.DeepEqual(expected, actual) {
+		t.Fatalf("Expected %#v, got %#v", expected, actual)
+	}
+}
+
+func TestSearchDevices(t *testing.T) {
+	ts := httpOKTestServer(testListDevicesOutputJSON)
+	defer ts.Close()
+	sess := fullSessionConfig()
+	sess.Config.Endpoint = ts.URL
+	client := NewController(sess)
+
+	expected := testListDevicesOutputExpected
+	actual, err := client.SearchDevices("switch")
+	if err != nil {
+		t.Fatalf("Bad: %s", err)
+	}
+
+	if !reflect.DeepEqual(expected, actual) {
+		t.Fatalf("Expected %#v, got %#v", expected, actual)
+	}
+}
+
+func TestSearchDevicesEscapesTerm(t *testing.T) {
+	cases := map[string]string{
+		"10.10.1.0/24": "/0123456789abcdefgh/devices/search/10.10.1.0%2F24/",
+		"foo?bar#baz":  "/0123456789abcdefgh/devices/search/foo%3Fbar%23baz/",
+	}
+	for term, expected := range cases {
+		var path string
+		ts := newHTTPTestServer(func(w http.ResponseWriter, r *http.Request) {
+			path = r.URL.EscapedPath()
+			w.Header().Add("Content-Type", "application/json")
+			http.Error(w, testListDevicesOutputJSON, http.StatusOK)
+		})
+		sess := fullSessionConfig()
+		sess.Config.Endpoint = ts.URL
+		client := NewController(sess)
+
+		_, err := client.SearchDevices(term)
+		ts.Close()
+		if err != nil {
+			t.Fatalf("%s: Bad: %s", term, err)
+		}
+		if path != expected {
+			t.Fatalf("%s: Expected path %s, got %s", term, expected, path)
+		}
+	}
+}
+
+func TestGetSubnetsForDevice(t *testing.T) {
+	ts := httpOKTestServer(testGetSubnetsForDeviceOutputJSON)
+	defer ts.Close()
+	sess := fullSessionConfig()
+	sess.Config.Endpoint = ts.URL
+	client := NewController(sess)
+
+	expected := testGetSubnetsForDeviceOutputExpected
+	actual, err := client.GetSubnetsForDevice(4)
+	if err != nil {
+		t.Fatalf("Bad: %s", err)
+	}
+
+	if !reflect.DeepEqual(expected, actual) {
+		t.Fatalf("Expected %#v, got %#v", expected, actual)
+	}
+}
+
+func TestGetAddressesForDevice(t *testing.T) {
+	ts := httpOKTestServer(testGetAddressesForDeviceOutputJSON)
+	defer ts.Close()
+	sess := fullSessionConfig()
+	sess.Config.Endpoint = ts.URL
+	client := NewController(sess)
+
+	expected := testGetAddressesForDeviceOutputExpected
+	actual, err := client.GetAddressesForDevice(4)
+	if err != nil {
+		t.Fatalf("Bad: %s", err)
+	}
+
+	if !reflect.DeepEqual(expected, actual) {
+		t.Fatalf("Expected %#v, got %#v", expected, actual)
+	}
+}
+
+func TestGetDeviceCustomFieldsSchema(t *testing.T) {
+	ts := httpOKTestServer(testGetDeviceCustomFieldsSchemaJSON)
+	defer ts.Close()
+	sess := fullSessionConfig()
+	sess.Config.Endpoint = ts.URL
+	client := NewController(sess)
+
+	expected := testGetDeviceCustomFieldsSchemaExpected
+	actual, err := client.GetDeviceCustomFieldsSchema()
+	if err != nil {
+		t.Fatalf("Bad: %s", err)
+	}
+
+	if !reflect.DeepEqual(expected, actual) {
+		t.Fatalf("Expected %#v, got %#v", expected, actual)
+	}
+}
+
+func TestUpdateDevice(t *testing.T) {
+	ts := httpOKTestServer(testUpdateDeviceOutputJSON)
+	defer ts.Close()
+	sess := fullSessionConfig()
+	sess.Config.Endpoint = ts.URL
+	client := NewController(sess)
+
+	in := testUpdateDeviceInput
+	expected := testUpdateDeviceOutputExpected
+	actual, err := client.UpdateDevice(in)
+	if err != nil {
+		t.Fatalf("Bad: %s", err)
+	}
+
+	if !reflect.DeepEqual(expected, actual) {
+		t.Fatalf("Expected %#v, got %#v", expected, actual)
+	}
+}
+
+func TestDeleteDevice(t *testing.T) {
+	ts := httpOKTestServer(testDeleteDeviceOutputJSON)
+	defer ts.Close()
+	sess := fullSessionConfig()
+	sess.Config.Endpoint = ts.URL
+	client := NewController(sess)
+
+	expected := testDeleteDeviceOutputExpected
+	actual, err := client.DeleteDevice(4)
+	if err != nil {
+		t.Fatalf("Bad: %s", err)
+	}
+
+	if !reflect.DeepEqual(expected, actual) {
+		t.Fatalf("Expected %#v, got %#v", expected, actual)
+	}
+}
+
+// testAccDeviceCRUDCreate tests the creation part of the devices controller
+// CRUD acceptance test, and returns the ID of the new device.
+func testAccDeviceCRUDCreate(t *testing.T, sess *session.Session, d Device) int {
+	c := NewController(sess)
+
+	id, err := c.CreateDeviceAndGetID(d)
+	if err != nil {
+		t.Fatalf("Create: Error creating device: %s", err)
+	}
+	return id
+}
+
+// testAccDeviceCRUDReadByID tests the read part of the devices controller
+// acceptance test, by fetching the device by ID.
+func testAccDeviceCRUDReadByID(t *testing.T, sess *session.Session, d Device) {
+	c := NewController(sess)
+
+	out, err := c.GetDeviceByID(d.ID)
+	if err != nil {
+		t.Fatalf("Can't find device by ID: %s", err)
+	}
+
+	// Update fields set by the server in the original
+	d.EditDate = out.EditDate
+	d.SNMPVersion = out.SNMPVersion
+	d.SNMPPort = out.SNMPPort
+	d.SNMPTimeout = out.SNMPTimeout
+
+	if !reflect.DeepEqual(d, out) {
+		t.Fatalf("ReadByID: Expected %#v, got %#v", d, out)
+	}
+}
+
+// testAccDeviceCRUDUpdate tests the update part of the devices controller
+// acceptance test.
+func testAccDeviceCRUDUpdate(t *testing.T, sess *session.Session, d Device) {
+	c := NewController(sess)
+
+	if _, err := c.UpdateDevice(d); err != nil {
+		t.Fatalf("Error updating device: %s", err)
+	}
+
+	testAccDeviceCRUDReadByID(t, sess, d)
+}
+
+// testAccDeviceCRUDDelete tests the delete part of the devices controller
+// acceptance test.
+func testAccDeviceCRUDDelete(t *testing.T, sess *session.Session, d Device) {
+	c := NewController(sess)
+
+	if _, err := c.DeleteDevice(d.ID); err != nil {
+		t.Fatalf("Error deleting device: %s", err)
+	}
+
+	// check to see if device is actually gone
+	if _, err := c.GetDeviceByID(d.ID); err == nil {
+		t.Fatalf("Device still present after delete")
+	}
+}
+
+// TestAccDeviceCRUD runs a full create-read-update-delete test for a PHPIPAM
+// device.
+func TestAccDeviceCRUD(t *testing.T) {
+	testacc.VetAccConditions(t)
+
+	sess := session.NewSession()
+	device := testCreateDeviceInput
+	device.ID = testAccDeviceCRUDCreate(t, sess, device)
+	testAccDeviceCRUDReadByID(t, sess, device)
+	device.Description = "Updating device!"
+	testAccDeviceCRUDUpdate(t, sess, device)
+	testAccDeviceCRUDDelete(t, sess, device)
+}
@@ -0,0 +1,194 @@
+// Package l2domains provides types and methods for working with the L2 domains
+// controller.
+package l2domains
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/paybyphone/phpipam-sdk-go/controllers/vlans"
+	"github.com/paybyphone/phpipam-sdk-go/phpipam"
+	"github.com/paybyphone/phpipam-sdk-go/phpipam/client"
+	"github.com/paybyphone/phpipam-sdk-go/phpipam/session"
+)
+
+// L2Domain represents a PHPIPAM L2 domain. L2 domains group VLANs, allowing
+// the same VLAN number to be used at different sites.
+type L2Domain struct {
+	// The L2 domain ID.
+	ID int `json:"id,string,omitempty"`
+
+	// The name of the L2 domain.
+	Name string `json:"name,omitempty"`
+
+	// A detailed description of the L2 domain.
+	Description string `json:"description,omitempty"`
+
+	// A semicolon-separated list of the IDs of the sections that this L2
+	// domain belongs to (i.e. "1;2;3").
+	Sections string `json:"sections,omitempty"`
+
+	// A map[string]interface{} of custom fields to set on the resource. Note
+	// that this functionality requires PHPIPAM 1.3 or higher with the "Nest
+	// custom fields" flag set on the specific API integration. If this is not
+	// enabled, this map will be nil on GETs and POSTs and PATCHes with this
+	// field set will fail. Use the explicit custom field functions instead.
+	CustomFields map[string]interface{} `json:"custom_fields,omitempty"`
+}
+
+// Controller is the base client for the L2 domains controller.
+type Controller struct {
+	client.Client
+}
+
+// NewController returns a new instance of the client for the L2 domains
+// controller.
+func NewController(sess *session.Session) *Controller {
+	c := &Controller{
+		Client: *client.NewClient(sess),
+	}
+	return c
+}
+
+// ListL2Domains lists all L2 domains.
+func (c *Controller) ListL2Domains() (out []L2Domain, err error) {
+	return c.ListL2DomainsWithContext(context.Background())
+}
+
+// ListL2DomainsWithContext is the same as ListL2Domains, but takes a
+// context.Context.
+func (c *Controller) ListL2DomainsWithContext(ctx context.Context) (out []L2Domain, err error) {
+	err = c.SendRequestWithContext(ctx, "GET", "/l2domains/", &struct{}{}, &out)
+	return
+}
+
+// CreateL2Domain creates a L2 domain by sending a POST request.
+func (c *Controller) CreateL2Domain(in L2Domain) (message string, err error) {
+	return c.CreateL2DomainWithContext(context.Background(), in)
+}
+
+// CreateL2DomainWithContext is the same as CreateL2Domain, but takes a
+// context.Context.
+func (c *Controller) CreateL2DomainWithContext(ctx context.Context, in L2Domain) (message string, err error) {
+	err = c.SendRequestWithContext(ctx, "POST", "/l2domains/", &in, &message)
+	return
+}
+
+// CreateL2DomainAndGetID creates a L2 domain by sending a POST request, and
+// returns the ID of the new L2 domain.
+func (c *Controller) CreateL2DomainAndGetID(in L2Domain) (id int, err error) {
+	return c.CreateL2DomainAndGetIDWithContext(context.Background(), in)
+}
+
+// CreateL2DomainAndGetIDWithContext is the same as CreateL2DomainAndGetID, but
+// takes a context.Context.
+func (c *Controller) CreateL2DomainAndGetIDWithContext(ctx context.Context, in L2Domain) (id int, err error) {
+	id, err = c.Client.CreateResourceWithContext(ctx, "/l2domains/", &in)
+	return
+}
+
+// CreateL2DomainAndGet creates a L2 domain by sending a POST request, and
+// returns the new L2 domain as read back from the API.
+func (c *Controller) CreateL2DomainAndGet(in L2Domain) (out L2Domain, err error) {
+	return c.CreateL2DomainAndGetWithContext(context.Background(), in)
+}
+
+// CreateL2DomainAndGetWithContext is the same as CreateL2DomainAndGet, but
+// takes a context.Context.
+func (c *Controller) CreateL2DomainAndGetWithContext(ctx context.Context, in L2Domain) (out L2Domain, err error) {
+	var id int
+	id, err = c.CreateL2DomainAndGetIDWithContext(ctx, in)
+	if err != nil {
+		return
+	}
+	out, err = c.GetL2DomainByIDWithContext(ctx, id)
+	return
+}
+
+// GetL2DomainByID GETs a L2 domain via its ID.
+func (c *Controller) GetL2DomainByID(id int) (out L2Domain, err error) {
+	return c.GetL2DomainByIDWithContext(context.Background(), id)
+}
+
+// GetL2DomainByIDWithContext is the same as GetL2DomainByID, but takes a
+// context.Context.
+func (c *Controller) GetL2DomainByIDWithContext(ctx context.Context, id int) (out L2Domain, err error) {
+	err = c.SendRequestWithContext(ctx, "GET", fmt.Sprintf("/l2domains/%d/", id), &struct{}{}, &out)
+	return
+}
+
+// GetVLANsInL2Domain GETs the VLANs that belong to a L2 domain, via a supplied
+// L2 domain ID.
+func (c *Controller) GetVLANsInL2Domain(id int) (out []vlans.VLAN, err error) {
+	return c.GetVLANsInL2DomainWithContext(context.Background(), id)
+}
+
+// GetVLANsInL2DomainWithContext is the same as GetVLANsInL2Domain, but takes a
+// context.Context.
+func (c *Controller) GetVLANsInL2DomainWithContext(ctx context.Context, id int) (out []vlans.VLAN, err error) {
+	err = c.SendRequestWithContext(ctx, "GET", fmt.Sprintf("/l2domains/%d/vlans/", id), &struct{}{}, &out)
+	return
+}
+
+// GetL2DomainCustomFieldsSchema GETs the custom fields for the L2 domains
+// controller via client.GetCustomFieldsSchema.
+func (c *Controller) GetL2DomainCustomFieldsSchema() (out map[string]phpipam.CustomField, err error) {
+	return c.GetL2DomainCustomFieldsSchemaWithContext(context.Background())
+}
+
+// GetL2DomainCustomFieldsSchemaWithContext is the same as
+// GetL2DomainCustomFieldsSchema, but takes a context.Context.
+func (c *Controller) GetL2DomainCustomFieldsSchemaWithContext(ctx context.Context) (out map[string]phpipam.CustomField, err error) {
+	out, err = c.Client.GetCustomFieldsSchemaWithContext(ctx, "l2domains")
+	return
+}
+
+// GetL2DomainCustomFields GETs the custom fields for a L2 domain via
+// client.GetCustomFields.
+func (c *Controller) GetL2DomainCustomFields(id int) (out map[string]interface{}, err error) {
+	return c.GetL2DomainCustomFieldsWithContext(context.Background(), id)
+}
+
+// GetL2DomainCustomFieldsWithContext is the same as GetL2DomainCustomFields,
+// but takes a context.Context.
+func (c *Controller) GetL2DomainCustomFieldsWithContext(ctx context.Context, id int) (out map[string]interface{}, err error) {
+	out, err = c.Client.GetCustomFieldsWithContext(ctx, id, "l2domains")
+	return
+}
+
+// UpdateL2Domain updates a L2 domain by sending a PATCH request.
+func (c *Controller) UpdateL2Domain(in L2Domain) (message string, err error) {
+	return c.UpdateL2DomainWithContext(context.Background(), in)
+}
+
+// UpdateL2DomainWithContext is the same as UpdateL2Domain, but takes a
+// context.Context.
+func (c *Controller) UpdateL2DomainWithContext(ctx context.Context, in L2Domain) (message string, err error) {
+	err = c.SendRequestWithContext(ctx, "PATCH", "/l2domains/", &in, &message)
+	return
+}
+
+// UpdateL2DomainCustomFields PATCHes the L2 domain's custom fields via
+// client.UpdateCustomFields.
+func (c *Controller) UpdateL2DomainCustomFields(id int, in map[string]interface{}) (message string, err error) {
+	return c.UpdateL2DomainCustomFieldsWithContext(context.Background(), id, in)
+}
+
+// UpdateL2DomainCustomFieldsWithContext is the same as
+// UpdateL2DomainCustomFields, but takes a context.Context.
+func (c *Controller) UpdateL2DomainCustomFieldsWithContext(ctx context.Context, id int, in map[string]interface{}) (message string, err error) {
+	message, err = c.Client.UpdateCustomFieldsWithContext(ctx, id, in, "l2domains")
+	return
+}
+
+// DeleteL2Domain deletes a L2 domain by its ID.
+func (c *Controller) DeleteL2Domain(id int) (message string, err error) {
+	return c.DeleteL2DomainWithContext(context.Background(), id)
+}
+
+// DeleteL2DomainWithContext is the same as DeleteL2Domain, but takes a
+// context.Context.
+func (c *Controller) DeleteL2DomainWithContext(ctx context.Context, id int) (message string, err error) {
+	err = c.SendRequestWithContext(ctx, "DELETE", fmt.Sprintf("/l2domains/%d/", id), &struct{}{}, &message)
+	return
+}
@@ -0,0 +1,430 @@
+package l2domains
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/paybyphone/phpipam-sdk-go/controllers/vlans"
+	"github.com/paybyphone/phpipam-sdk-go/phpipam"
+	"github.com/paybyphone/phpipam-sdk-go/phpipam/session"
+	"github.com/paybyphone/phpipam-sdk-go/testacc"
+)
+
+var testCreateL2DomainInput = L2Domain{
+	Name:        "site1",
+	Description: "Site 1 L2 domain",
+}
+
+const testCreateL2DomainOutputExpected = `L2 domain created`
+const testCreateL2DomainOutputJSON = `
+{
+  "code": 201,
+  "success": true,
+  "data": "L2 domain created"
+}
+`
+
+const testCreateL2DomainAndGetIDOutputJSON = `
+{
+  "code": 201,
+  "success": true,
+  "message": "L2domain created",
+  "id": "2",
+  "time": 0.008
+}
+`
+
+var testGetL2DomainByIDOutputExpected = L2Domain{
+	ID:          2,
+	Name:        "site1",
+	Description: "Site 1 L2 domain",
+	Sections:    "1;2",
+}
+
+const testGetL2DomainByIDOutputJSON = `
+{
+  "code": 200,
+  "success": true,
+  "data": {
+    "id": "2",
+    "name": "site1",
+    "description": "Site 1 L2 domain",
+    "sections": "1;2",
+    "links": [
+      {
+        "rel": "self",
+        "href": "/api/test/l2domains/2/"
+      }
+    ]
+  }
+}
+`
+
+var testListL2DomainsOutputExpected = []L2Domain{
+	L2Domain{
+		ID:          1,
+		Name:        "default",
+		Description: "default L2 domain",
+	},
+	L2Domain{
+		ID:          2,
+		Name:        "site1",
+		Description: "Site 1 L2 domain",
+		Sections:    "1;2",
+	},
+}
+
+const testListL2DomainsOutputJSON = `
+{
+  "code": 200,
+  "success": true,
+  "data": [
+    {
+      "id": "1",
+      "name": "default",
+      "description": "default L2 domain",
+      "sections": null
+    },
+    {
+      "id": "2",
+      "name": "site1",
+      "description": "Site 1 L2 domain",
+      "sections": "1;2"
+    }
+  ]
+}
+`
+
+var testGetVLANsInL2DomainOutputExpected = []vlans.VLAN{
+	vlans.VLAN{
+		ID:       4,
+		DomainID: 2,
+		Name:     "barlan",
+		Number:   1000,
+	},
+}
+
+const testGetVLANsInL2DomainOutputJSON = `
+{
+  "code": 200,
+  "success": true,
+  "data": [
+    {
+      "id": "4",
+      "domainId": "2",
+      "name": "barlan",
+      "number": "1000",
+      "description": null,
+      "editDate": null
+    }
+  ]
+}
+`
+
+var testGetL2DomainCustomFieldsSchemaExpected = map[string]phpipam.CustomField{
+	"CustomTestL2Domains": phpipam.CustomField{
+		Name:    "CustomTestL2Domains",
+		Type:    "varchar(255)",
+		Comment: "Test field for L2 domains controller",
+		Null:    "YES",
+		Default: "",
+	},
+}
+
+const testGetL2DomainCustomFieldsSchemaJSON = `
+{
+  "code": 200,
+  "success": true,
+  "data": {
+    "CustomTestL2Domains": {
+      "name": "CustomTestL2Domains",
+      "type": "varchar(255)",
+      "Comment": "Test field for L2 domains controller",
+      "Null": "YES",
+      "Default": ""
+    }
+  }
+}
+`
+
+var testUpdateL2DomainInput = L2Domain{
+	ID:          2,
+	Description: "foobat",
+}
+
+const testUpdateL2DomainOutputExpected = `L2 domain updated`
+const testUpdateL2DomainOutputJSON = `
+{
+  "code": 200,
+  "success": true,
+  "data": "L2 domain updated"
+}
+`
+
+const testDeleteL2DomainOutputExpected = `L2 domain deleted`
+const testDeleteL2DomainOutputJSON = `
+{
+  "code": 200,
+  "success": true,
+  "data": "L2 domain deleted"
+}
+`
+
+func newHTTPTestServer(f func(w http.ResponseWriter, r *http.Request)) *httptest.Server {
+	ts := httptest.NewServer(http.HandlerFunc(f))
+	return ts
+}
+
+func httpOKTestServer(output string) *httptest.Server {
+	return newHTTPTestServer(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Content-Type", "application/json")
+		http.Error(w, output, http.StatusOK)
+	})
+}
+
+func httpCreatedTestServer(output string) *httptest.Server {
+	return newHTTPTestServer(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Content-Type", "application/json")
+		http.Error(w, output, http.StatusCreated)
+	})
+}
+
+func fullSessionConfig() *session.Session {
+	return &session.Session{
+		Config: phpipam.Config{
+			AppID:    "0123456789abcdefgh",
+			Password: "changeit",
+			Username: "nobody",
+		},
+		Token: session.Token{
+			String: "foobarbazboop",
+		},
+	}
+}
+
+func TestListL2Domains(t *testing.T) {
+	ts := httpOKTestServer(testListL2DomainsOutputJSON)
+	defer ts.Close()
+	sess := fullSessionConfig()
+	sess.Config.Endpoint = ts.URL
+	client := NewController(sess)
+
+	expected := testListL2DomainsOutputExpected
+	actual, err := client.ListL2Domains()
+	if err != nil {
+		t.Fatalf("Bad: %s", err)
+	}
+
+	if !reflect.DeepEqual(expected, actual) {
+		t.Fatalf("Expected %#v, got %#v", expected, actual)
+	}
+}
+
+func TestCreateL2Domain(t *testing.T) {
+	ts := httpCreatedTestServer(testCreateL2DomainOutputJSON)
+	defer ts.Close()
+	sess := fullSessionConfig()
+	sess.Config.Endpoint = ts.URL
+	client := NewController(sess)
+
+	in := testCreateL2DomainInput
+	expected := testCreateL2DomainOutputExpected
+	actual, err := client.CreateL2Domain(in)
+	if err != nil {
+		t.Fatalf("Bad: %s", err)
+	}
+
+	if !reflect.DeepEqual(expected, actual) {
+		t.Fatalf("Expected %#v, got %#v", expected, actual)
+	}
+}
+
+func TestCreateL2DomainAndGet(t *testing.T) {
+	ts := newHTTPTestServer(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Content-Type", "application/json")
+		switch {
+		case r.Method == "POST" && r.URL.Path == "/0123456789abcdefgh/l2domains/":
+			http.Error(w, testCreateL2DomainAndGetIDOutputJSON, http.StatusCreated)
+		case r.Method == "GET" && r.URL.Path == "/0123456789abcdefgh/l2domains/2/":
+			http.Error(w, testGetL2DomainByIDOutputJSON, http.StatusOK)
+		default:
+			http.Error(w, "not found", http.StatusNotFound)
+		}
+	})
+	defer ts.Close()
+	sess := fullSessionConfig()
+	sess.Config.Endpoint = ts.URL
+	client := NewController(sess)
+
+	in := testCreateL2DomainInput
+	expected := testGetL2DomainByIDOutputExpected
+	actual, err := client.CreateL2DomainAndGet(in)
+	if err != nil {
+		t.Fatalf("Bad: %s", err)
+	}
+
+	if !reflect.DeepEqual(expected, actual) {
+		t.Fatalf("Expected %#v, got %#v", expected, actual)
+	}
+}
+
+func TestGetL2DomainByID(t *testing.T) {
+	ts := httpOKTestServer(testGetL2DomainByIDOutputJSON)
+	defer ts.Close()
+	sess := fullSessionConfig()
+	sess.Config.Endpoint = ts.URL
+	client := NewController(sess)
+
+	expected := testGetL2DomainByIDOutputExpected
+	actual, err := client.GetL2DomainByID(2)
+	if err != nil {
+		t.Fatalf("Bad: %s", err)
+	}
+
+	if !reflect.DeepEqual(expected, actual) {
+		t.Fatalf("Expected %#v, got %#v", expected, actual)
+	}
+}
+
+func TestGetVLANsInL2Domain(t *testing.T) {
+	ts := httpOKTestServer(testGetVLANsInL2DomainOutputJSON)
+	defer ts.Close()
+	sess := fullSessionConfig()
+	sess.Config.Endpoint = ts.URL
+	client := NewController(sess)
+
+	expected := testGetVLANsInL2DomainOutputExpected
+	actual, err := client.GetVLANsInL2Domain(2)
+	if err != nil {
+		t.Fatalf("Bad: %s", err)
+	}
+
+	if !reflect.DeepEqual(expected, actual) {
+		t.Fatalf("Expected %#v, got %#v", expected, actual)
+	}
+}
+
+func TestGetL2DomainCustomFieldsSchema(t *testing.T) {
+	ts := httpOKTestServer(testGetL2DomainCustomFieldsSchemaJSON)
+	defer ts.Close()
+	sess := fullSessionConfig()
+	sess.Config.Endpoint = ts.URL
+	client := NewController(sess)
+
+	expected := testGetL2DomainCustomFieldsSchemaExpected
+	actual, err := client.GetL2DomainCustomFieldsSchema()
+	if err != nil {
+		t.Fatalf("Bad: %s", err)
+	}
+
+	if !reflect.DeepEqual(expected, actual) {
+		t.Fatalf("Expected %#v, got %#v", expected, actual)
+	}
+}
+
+func TestUpdateL2Domain(t *testing.T) {
+	ts := httpOKTestServer(testUpdateL2DomainOutputJSON)
+	defer ts.Close()
+	sess := fullSessionConfig()
+	sess.Config.Endpoint = ts.URL
+	client := NewController(sess)
+
+	in := testUpdateL2DomainInput
+	expected := testUpdateL2DomainOutputExpected
+	actual, err := client.UpdateL2Domain(in)
+	if err != nil {
+		t.Fatalf("Bad: %s", err)
+	}
+
+	if !reflect.DeepEqual(expected, actual) {
+		t.Fatalf("Expected %#v, got %#v", expected, actual)
+	}
+}
+
+func TestDeleteL2Domain(t *testing.T) {
+	ts := httpOKTestServer(testDeleteL2DomainOutputJSON)
+	defer ts.Close()
+	sess := fullSessionConfig()
+	sess.Config.Endpoint = ts.URL
+	client := NewController(sess)
+
+	expected := testDeleteL2DomainOutputExpected
+	actual, err := client.DeleteL2Domain(2)
+	if err != nil {
+		t.Fatalf("Bad: %s", err)
+	}
+
+	if !reflect.DeepEqual(expected, actual) {
+		t.Fatalf("Expected %#v, got %#v", expected, actual)
+	}
+}
+
+// testAccL2DomainCRUDCreate tests the creation part of the L2 domains
+// controller CRUD acceptance test, and returns the ID of the new L2 domain.
+func testAccL2DomainCRUDCreate(t *testing.T, sess *session.Session, d L2Domain) int {
+	c := NewController(sess)
+
+	id, err := c.CreateL2DomainAndGetID(d)
+	if err != nil {
+		t.Fatalf("Create: Error creating L2 domain: %s", err)
+	}
+	return id
+}
+
+// testAccL2DomainCRUDReadByID tests the read part of the L2 domains controller
+// acceptance test, by fetching the L2 domain by ID.
+func testAccL2DomainCRUDReadByID(t *testing.T, sess *session.Session, d L2Domain) {
+	c := NewController(sess)
+
+	out, err := c.GetL2DomainByID(d.ID)
+	if err != nil {
+		t.Fatalf("Can't find L2 domain by ID: %s", err)
+	}
+
+	if !reflect.DeepEqual(d, out) {
+		t.Fatalf("ReadByID: Expected %#v, got %#v", d, out)
+	}
+}
+
+// testAccL2DomainCRUDUpdate tests the update part of the L2 domains
+// controller acceptance test.
+func testAccL2DomainCRUDUpdate(t *testing.T, sess *session.Session, d L2Domain) {
+	c := NewController(sess)
+
+	if _, err := c.UpdateL2Domain(d); err != nil {
+		t.Fatalf("Error updating L2 domain: %s", err)
+	}
+
+	testAccL2DomainCRUDReadByID(t, sess, d)
+}
+
+// testAccL2DomainCRUDDelete tests the delete part of the L2 domains
+// controller acceptance test.
+func testAccL2DomainCRUDDelete(t *testing.T, sess *session.Session, d L2Domain) {
+	c := NewController(sess)
+
+	if _, err := c.DeleteL2Domain(d.ID); err != nil {
+		t.Fatalf("Error deleting L2 domain: %s", err)
+	}
+
+	// check to see if L2 domain is actually gone
+	if _, err := c.GetL2DomainByID(d.ID); err == nil {
+		t.Fatalf("L2 domain still present after delete")
+	}
+}
+
+// TestAccL2DomainCRUD runs a full create-read-update-delete test for a PHPIPAM
+// L2 domain.
+func TestAccL2DomainCRUD(t *testing.T) {
+	testacc.VetAccConditions(t)
+
+	sess := session.NewSession()
+	domain := testCreateL2DomainInput
+	domain.ID = testAccL2DomainCRUDCreate(t, sess, domain)
+	testAccL2DomainCRUDReadByID(t, sess, domain)
+	domain.Description = "Updating L2 domain!"
+	testAccL2DomainCRUDUpdate(t, sess, domain)
+	testAccL2DomainCRUDDelete(t, sess, domain)
+}
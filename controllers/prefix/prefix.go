@@ -0,0 +1,177 @@
+// Package prefix provides types and methods for working with the prefix
+// controller.
+//
+// The prefix controller allocates subnets and addresses out of pools of
+// subnets that are tagged with a customer type, via the custom field
+// configured for the API application in PHPIPAM. This requires PHPIPAM 1.3 or
+// higher.
+package prefix
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/paybyphone/phpipam-sdk-go/controllers/addresses"
+	"github.com/paybyphone/phpipam-sdk-go/controllers/subnets"
+	"github.com/paybyphone/phpipam-sdk-go/phpipam/client"
+	"github.com/paybyphone/phpipam-sdk-go/phpipam/session"
+)
+
+// IPVersion is the IP version to allocate subnets or addresses for.
+type IPVersion string
+
+const (
+	// IPv4 allocates IPv4 subnets or addresses.
+	IPv4 IPVersion = "v4"
+
+	// IPv6 allocates IPv6 subnets or addresses.
+	IPv6 IPVersion = "v6"
+)
+
+// validate returns an error if v is not IPv4 or IPv6.
+func (v IPVersion) validate() error {
+	switch v {
+	case IPv4, IPv6:
+		return nil
+	}
+	return fmt.Errorf("Invalid IP version %q, needs to be %s or %s", string(v), IPv4, IPv6)
+}
+
+// Controller is the base client for the Prefix controller.
+type Controller struct {
+	client.Client
+}
+
+// NewController returns a new instance of the client for the Prefix
+// controller.
+func NewController(sess *session.Session) *Controller {
+	c := &Controller{
+		Client: *client.NewClient(sess),
+	}
+	return c
+}
+
+// ListSubnets lists the subnets that new subnets are allocated from for the
+// supplied customer type. If version is blank, subnets of all IP versions are
+// returned.
+func (c *Controller) ListSubnets(customerType string, version IPVersion) (out []subnets.Subnet, err error) {
+	return c.ListSubnetsWithContext(context.Background(), customerType, version)
+}
+
+// ListSubnetsWithContext is the same as ListSubnets, but takes a
+// context.Context.
+func (c *Controller) ListSubnetsWithContext(ctx context.Context, customerType string, version IPVersion) (out []subnets.Subnet, err error) {
+	uri := fmt.Sprintf("/prefix/%s/", customerType)
+	if version != "" {
+		uri = fmt.Sprintf("/prefix/%s/%s/", customerType, version)
+	}
+	err = c.SendRequestWithContext(ctx, "GET", uri, &struct{}{}, &out)
+	return
+}
+
+// ListAddressSubnets lists the subnets that new addresses are allocated from
+// for the supplied customer type. If version is blank, subnets of all IP
+// versions are returned.
+func (c *Controller) ListAddressSubnets(customerType string, version IPVersion) (out []subnets.Subnet, err error) {
+	return c.ListAddressSubnetsWithContext(context.Background(), customerType, version)
+}
+
+// ListAddressSubnetsWithContext is the same as ListAddressSubnets, but takes a
+// context.Context.
+func (c *Controller) ListAddressSubnetsWithContext(ctx context.Context, customerType string, version IPVersion) (out []subnets.Subnet, err error) {
+	uri := fmt.Sprintf("/prefix/%s/address/", customerType)
+	if version != "" {
+		uri = fmt.Sprintf("/prefix/%s/address/%s/", customerType, version)
+	}
+	err = c.SendRequestWithContext(ctx, "GET", uri, &struct{}{}, &out)
+	return
+}
+
+// GetFirstFreeSubnet GETs the first free subnet with the supplied mask for a
+// customer type and returns it in CIDR notation (i.e. "10.10.1.0/24").
+//
+// version needs to be IPv4 or IPv6. This does not reserve the subnet - use
+// CreateFirstFreeSubnet for that.
+func (c *Controller) GetFirstFreeSubnet(customerType string, version IPVersion, mask int) (out string, err error) {
+	return c.GetFirstFreeSubnetWithContext(context.Background(), customerType, version, mask)
+}
+
+// GetFirstFreeSubnetWithContext is the same as GetFirstFreeSubnet, but takes a
+// context.Context.
+func (c *Controller) GetFirstFreeSubnetWithContext(ctx context.Context, customerType string, version IPVersion, mask int) (out string, err error) {
+	if err = version.validate(); err != nil {
+		return
+	}
+	err = c.SendRequestWithContext(ctx, "GET", fmt.Sprintf("/prefix/%s/%s/%d/", customerType, version, mask), &struct{}{}, &out)
+	return
+}
+
+// GetFirstFreeAddress GETs the first free address for a customer type and
+// returns it as a string.
+//
+// version needs to be IPv4 or IPv6. This does not reserve the address - use
+// CreateFirstFreeAddress for that.
+func (c *Controller) GetFirstFreeAddress(customerType string, version IPVersion) (out string, err error) {
+	return c.GetFirstFreeAddressWithContext(context.Background(), customerType, version)
+}
+
+// GetFirstFreeAddressWithContext is the same as GetFirstFreeAddress, but takes
+// a context.Context.
+func (c *Controller) GetFirstFreeAddressWithContext(ctx context.Context, customerType string, version IPVersion) (out string, err error) {
+	if err = version.validate(); err != nil {
+		return
+	}
+	err = c.SendRequestWithContext(ctx, "GET", fmt.Sprintf("/prefix/%s/%s/address/", customerType, version), &struct{}{}, &out)
+	return
+}
+
+// CreateFirstFreeSubnet allocates the first free subnet with the supplied mask
+// for a customer type by sending a POST request, and returns the new subnet
+// as read back from the API. version needs to be IPv4 or IPv6.
+//
+// Fields set in in, such as the description, are set on the new subnet. The
+// subnet address, mask, and parent subnet are chosen by PHPIPAM and should be
+// left blank.
+func (c *Controller) CreateFirstFreeSubnet(customerType string, version IPVersion, mask int, in subnets.Subnet) (out subnets.Subnet, err error) {
+	return c.CreateFirstFreeSubnetWithContext(context.Background(), customerType, version, mask, in)
+}
+
+// CreateFirstFreeSubnetWithContext is the same as CreateFirstFreeSubnet, but
+// takes a context.Context.
+func (c *Controller) CreateFirstFreeSubnetWithContext(ctx context.Context, customerType string, version IPVersion, mask int, in subnets.Subnet) (out subnets.Subnet, err error) {
+	if err = version.validate(); err != nil {
+		return
+	}
+	var id int
+	id, err = c.Client.CreateResourceWithContext(ctx, fmt.Sprintf("/prefix/%s/%s/%d/", customerType, version, mask), &in)
+	if err != nil {
+		return
+	}
+	out, err = subnets.NewController(c.Session).GetSubnetByIDWithContext(ctx, id)
+	return
+}
+
+// CreateFirstFreeAddress allocates the first free address for a customer type
+// by sending a POST request, and returns the new address as read back from
+// the API. version needs to be IPv4 or IPv6.
+//
+// Fields set in in, such as the hostname, are set on the new address. The IP
+// address and subnet are chosen by PHPIPAM and should be left blank.
+func (c *Controller) CreateFirstFreeAddress(customerType string, version IPVersion, in addresses.Address) (out addresses.Address, err error) {
+	return c.CreateFirstFreeAddressWithContext(context.Background(), customerType, version, in)
+}
+
+// CreateFirstFreeAddressWithContext is the same as CreateFirstFreeAddress, but
+// takes a context.Context.
+func (c *Controller) CreateFirstFreeAddressWithContext(ctx context.Context, customerType string, version IPVersion, in addresses.Address) (out addresses.Address, err error) {
+	if err = version.validate(); err != nil {
+		return
+	}
+	var id int
+	id, err = c.Client.CreateResourceWithContext(ctx, fmt.Sprintf("/prefix/%s/%s/address/", customerType, version), &in)
+	if err != nil {
+		return
+	}
+	out, err = addresses.NewController(c.Session).GetAddressByIDWithContext(ctx, id)
+	return
+}
@@ -0,0 +1,363 @@
+package prefix
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync/atomic"
+	"testing"
+
+	"github.com/paybyphone/phpipam-sdk-go/controllers/addresses"
+	"github.com/paybyphone/phpipam-sdk-go/controllers/subnets"
+	"github.com/paybyphone/phpipam-sdk-go/phpipam"
+	"github.com/paybyphone/phpipam-sdk-go/phpipam/session"
+)
+
+var testListSubnetsOutputExpected = []subnets.Subnet{
+	subnets.Subnet{
+		ID:            3,
+		SubnetAddress: "10.10.0.0",
+		Mask:          16,
+		SectionID:     1,
+		CustomFields: map[string]interface{}{
+			"custom_customer_type": "dev",
+		},
+	},
+}
+
+const testListSubnetsOutputJSON = `
+{
+  "code": 200,
+  "success": true,
+  "data": [
+    {
+      "id": "3",
+      "subnet": "10.10.0.0",
+      "mask": "16",
+      "sectionId": "1",
+      "custom_fields": {
+        "custom_customer_type": "dev"
+      }
+    }
+  ]
+}
+`
+
+const testGetFirstFreeSubnetOutputExpected = `10.10.1.0/24`
+const testGetFirstFreeSubnetOutputJSON = `
+{
+  "code": 200,
+  "success": true,
+  "data": "10.10.1.0/24"
+}
+`
+
+const testGetFirstFreeAddressOutputExpected = `10.10.1.5`
+const testGetFirstFreeAddressOutputJSON = `
+{
+  "code": 200,
+  "success": true,
+  "data": "10.10.1.5"
+}
+`
+
+var testCreateFirstFreeSubnetInput = subnets.Subnet{
+	Description: "dev allocation",
+}
+
+const testCreateFirstFreeSubnetOutputJSON = `
+{
+  "code": 201,
+  "success": true,
+  "message": "Subnet created",
+  "id": "8",
+  "data": "10.10.1.0/24",
+  "time": 0.02
+}
+`
+
+var testCreateFirstFreeSubnetOutputExpected = subnets.Subnet{
+	ID:             8,
+	SubnetAddress:  "10.10.1.0",
+	Mask:           24,
+	Description:    "dev allocation",
+	SectionID:      1,
+	MasterSubnetID: 3,
+}
+
+const testGetSubnetByIDOutputJSON = `
+{
+  "code": 200,
+  "success": true,
+  "data": {
+    "id": "8",
+    "subnet": "10.10.1.0",
+    "mask": "24",
+    "description": "dev allocation",
+    "sectionId": "1",
+    "masterSubnetId": "3"
+  }
+}
+`
+
+var testCreateFirstFreeAddressInput = addresses.Address{
+	Hostname: "host1.dev.local",
+}
+
+const testCreateFirstFreeAddressOutputJSON = `
+{
+  "code": 201,
+  "success": true,
+  "message": "Address created",
+  "id": "21",
+  "data": "10.10.1.5",
+  "time": 0.01
+}
+`
+
+var testCreateFirstFreeAddressOutputExpected = addresses.Address{
+	ID:        21,
+	SubnetID:  8,
+	IPAddress: "10.10.1.5",
+	Hostname:  "host1.dev.local",
+}
+
+const testGetAddressByIDOutputJSON = `
+{
+  "code": 200,
+  "success": true,
+  "data": {
+    "id": "21",
+    "subnetId": "8",
+    "ip": "10.10.1.5",
+    "hostname": "host1.dev.local"
+  }
+}
+`
+
+func newHTTPTestServer(f func(w http.ResponseWriter, r *http.Request)) *httptest.Server {
+	ts := httptest.NewServer(http.HandlerFunc(f))
+	return ts
+}
+
+// httpPathTestServer returns a test server that responds with output to GET
+// requests for path, and with a 404 otherwise.
+func httpPathTestServer(path, output string) *httptest.Server {
+	return newHTTPTestServer(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Content-Type", "application/json")
+		if r.Method != "GET" || r.URL.Path != path {
+			http.Error(w, "not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, output, http.StatusOK)
+	})
+}
+
+func fullSessionConfig() *session.Session {
+	return &session.Session{
+		Config: phpipam.Config{
+			AppID:    "0123456789abcdefgh",
+			Password: "changeit",
+			Username: "nobody",
+		},
+		Token: session.Token{
+			String: "foobarbazboop",
+		},
+	}
+}
+
+func TestListSubnets(t *testing.T) {
+	ts := httpPathTestServer("/0123456789abcdefgh/prefix/dev/", testListSubnetsOutputJSON)
+	defer ts.Close()
+	sess := fullSessionConfig()
+	sess.Config.Endpoint = ts.URL
+	client := NewController(sess)
+
+	expected := testListSubnetsOutputExpected
+	actual, err := client.ListSubnets("dev", "")
+	if err != nil {
+		t.Fatalf("Bad: %s", err)
+	}
+
+	if !reflect.DeepEqual(expected, actual) {
+		t.Fatalf("Expected %#v, got %#v", expected, actual)
+	}
+}
+
+func TestListSubnetsWithVersion(t *testing.T) {
+	ts := httpPathTestServer("/0123456789abcdefgh/prefix/dev/v4/", testListSubnetsOutputJSON)
+	defer ts.Close()
+	sess := fullSessionConfig()
+	sess.Config.Endpoint = ts.URL
+	client := NewController(sess)
+
+	expected := testListSubnetsOutputExpected
+	actual, err := client.ListSubnets("dev", IPv4)
+	if err != nil {
+		t.Fatalf("Bad: %s", err)
+	}
+
+	if !reflect.DeepEqual(expected, actual) {
+		t.Fatalf("Expected %#v, got %#v", expected, actual)
+	}
+}
+
+func TestListAddressSubnets(t *testing.T) {
+	ts := httpPathTestServer("/0123456789abcdefgh/prefix/dev/address/v4/", testListSubnetsOutputJSON)
+	defer ts.Close()
+	sess := fullSessionConfig()
+	sess.Config.Endpoint = ts.URL
+	client := NewController(sess)
+
+	expected := testListSubnetsOutputExpected
+	actual, err := client.ListAddressSubnets("dev", IPv4)
+	if err != nil {
+		t.Fatalf("Bad: %s", err)
+	}
+
+	if !reflect.DeepEqual(expected, actual) {
+		t.Fatalf("Expected %#v, got %#v", expected, actual)
+	}
+}
+
+func TestGetFirstFreeSubnet(t *testing.T) {
+	ts := httpPathTestServer("/0123456789abcdefgh/prefix/dev/v4/24/", testGetFirstFreeSubnetOutputJSON)
+	defer ts.Close()
+	sess := fullSessionConfig()
+	sess.Config.Endpoint = ts.URL
+	client := NewController(sess)
+
+	expected := testGetFirstFreeSubnetOutputExpected
+	actual, err := client.GetFirstFreeSubnet("dev", IPv4, 24)
+	if err != nil {
+		t.Fatalf("Bad: %s", err)
+	}
+
+	if expected != actual {
+		t.Fatalf("Expected %s, got %s", expected, actual)
+	}
+}
+
+func TestGetFirstFreeAddress(t *testing.T) {
+	ts := httpPathTestServer("/0123456789abcdefgh/prefix/dev/v4/address/", testGetFirstFreeAddressOutputJSON)
+	defer ts.Close()
+	sess := fullSessionConfig()
+	sess.Config.Endpoint = ts.URL
+	client := NewController(sess)
+
+	expected := testGetFirstFreeAddressOutputExpected
+	actual, err := client.GetFirstFreeAddress("dev", IPv4)
+	if err != nil {
+		t.Fatalf("Bad: %s", err)
+	}
+
+	if expected != actual {
+		t.Fatalf("Expected %s, got %s", expected, actual)
+	}
+}
+
+func TestCreateFirstFreeSubnet(t *testing.T) {
+	ts := newHTTPTestServer(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Content-Type", "application/json")
+		switch {
+		case r.Method == "POST" && r.URL.Path == "/0123456789abcdefgh/prefix/dev/v4/24/":
+			http.Error(w, testCreateFirstFreeSubnetOutputJSON, http.StatusCreated)
+		case r.Method == "GET" && r.URL.Path == "/0123456789abcdefgh/subnets/8/":
+			http.Error(w, testGetSubnetByIDOutputJSON, http.StatusOK)
+		default:
+			http.Error(w, "not found", http.StatusNotFound)
+		}
+	})
+	defer ts.Close()
+	sess := fullSessionConfig()
+	sess.Config.Endpoint = ts.URL
+	client := NewController(sess)
+
+	in := testCreateFirstFreeSubnetInput
+	expected := testCreateFirstFreeSubnetOutputExpected
+	actual, err := client.CreateFirstFreeSubnet("dev", IPv4, 24, in)
+	if err != nil {
+		t.Fatalf("Bad: %s", err)
+	}
+
+	if !reflect.DeepEqual(expected, actual) {
+		t.Fatalf("Expected %#v, got %#v", expected, actual)
+	}
+}
+
+func TestCreateFirstFreeAddress(t *testing.T) {
+	ts := newHTTPTestServer(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Content-Type", "application/json")
+		switch {
+		case r.Method == "POST" && r.URL.Path == "/0123456789abcdefgh/prefix/dev/v4/address/":
+			http.Error(w, testCreateFirstFreeAddressOutputJSON, http.StatusCreated)
+		case r.Method == "GET" && r.URL.Path == "/0123456789abcdefgh/addresses/21/":
+			http.Error(w, testGetAddressByIDOutputJSON, http.StatusOK)
+		default:
+			http.Error(w, "not found", http.StatusNotFound)
+		}
+	})
+	defer ts.Close()
+	sess := fullSessionConfig()
+	sess.Config.Endpoint = ts.URL
+	client := NewController(sess)
+
+	in := testCreateFirstFreeAddressInput
+	expected := testCreateFirstFreeAddressOutputExpected
+	actual, err := client.CreateFirstFreeAddress("dev", IPv4, in)
+	if err != nil {
+		t.Fatalf("Bad: %s", err)
+	}
+
+	if !reflect.DeepEqual(expected, actual) {
+		t.Fatalf("Expected %#v, got %#v", expected, actual)
+	}
+}
+
+func TestInvalidIPVersion(t *testing.T) {
+	var calls int32
+	ts := newHTTPTestServer(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		http.Error(w, "not found", http.StatusNotFound)
+	})
+	defer ts.Close()
+	sess := fullSessionConfig()
+	sess.Config.Endpoint = ts.URL
+	client := NewController(sess)
+
+	funcs := map[string]func(IPVersion) error{
+		"GetFirstFreeSubnet": func(v IPVersion) error {
+			_, err := client.GetFirstFreeSubnet("dev", v, 24)
+			return err
+		},
+		"GetFirstFreeAddress": func(v IPVersion) error {
+			_, err := client.GetFirstFreeAddress("dev", v)
+			return err
+		},
+		"CreateFirstFreeSubnet": func(v IPVersion) error {
+			_, err := client.CreateFirstFreeSubnet("dev", v, 24, testCreateFirstFreeSubnetInput)
+			return err
+		},
+		"CreateFirstFreeAddress": func(v IPVersion) error {
+			_, err := client.CreateFirstFreeAddress("dev", v, testCreateFirstFreeAddressInput)
+			return err
+		},
+	}
+	for name, fn := range funcs {
+		for _, v := range []IPVersion{"", "v5"} {
+			err := fn(v)
+			if err == nil {
+				t.Fatalf("%s(%q): Expected error, got none", name, v)
+			}
+			expected := fmt.Sprintf("Invalid IP version %q, needs to be v4 or v6", string(v))
+			if err.Error() != expected {
+				t.Fatalf("%s(%q): Expected %q, got %q", name, v, expected, err.Error())
+			}
+		}
+	}
+
+	if calls != 0 {
+		t.Fatalf("Expected no requests, got %d", calls)
+	}
+}
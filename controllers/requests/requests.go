@@ -0,0 +1,249 @@
+// Package requests provides types and methods for working with the IP
+// requests controller.
+//
+// IP requests allow users to request an address in a subnet that has
+// AllowRequests set. A request starts out pending, and is then either approved
+// or rejected by an administrator. Once processed, a request cannot change
+// state again.
+package requests
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/paybyphone/phpipam-sdk-go/phpipam"
+	"github.com/paybyphone/phpipam-sdk-go/phpipam/client"
+	"github.com/paybyphone/phpipam-sdk-go/phpipam/session"
+)
+
+// Status is the state of an IP request.
+type Status int
+
+const (
+	// StatusPending is the state of a request that has not been processed.
+	StatusPending Status = iota
+
+	// StatusApproved is the state of a request that has been approved.
+	StatusApproved
+
+	// StatusRejected is the state of a request that has been rejected.
+	StatusRejected
+)
+
+// String implements fmt.Stringer for Status.
+func (s Status) String() string {
+	switch s {
+	case StatusPending:
+		return "pending"
+	case StatusApproved:
+		return "approved"
+	case StatusRejected:
+		return "rejected"
+	}
+	return fmt.Sprintf("Status(%d)", int(s))
+}
+
+// CanTransitionTo returns true if a request in state s can be moved to state
+// t. Only pending requests can be approved or rejected.
+func (s Status) CanTransitionTo(t Status) bool {
+	return s == StatusPending && (t == StatusApproved || t == StatusRejected)
+}
+
+// IPRequest represents a PHPIPAM IP address request.
+type IPRequest struct {
+	// The ID of the request.
+	ID int `json:"id,string,omitempty"`
+
+	// The ID of the subnet that the address is requested in.
+	SubnetID int `json:"subnetId,string,omitempty"`
+
+	// The requested IP address. If this is blank, the first free address in
+	// the subnet is assigned on approval.
+	IPAddress string `json:"ip_addr,omitempty"`
+
+	// A detailed description of the requested address.
+	Description string `json:"description,omitempty"`
+
+	// A hostname for the requested address.
+	Hostname string `json:"hostname,omitempty"`
+
+	// The MAC address for the requested address.
+	MACAddress string `json:"mac,omitempty"`
+
+	// The owner of the requested address.
+	Owner string `json:"owner,omitempty"`
+
+	// The tag ID that the address gets on approval.
+	Tag int `json:"state,string,omitempty"`
+
+	// The email address of the requester.
+	Requester string `json:"requester,omitempty"`
+
+	// A comment from the requester.
+	Comment string `json:"comment,omitempty"`
+
+	// true if the request has been processed (approved or rejected).
+	Processed phpipam.BoolIntString `json:"processed,omitempty"`
+
+	// true if the request was approved. Only meaningful if Processed is true.
+	Accepted phpipam.BoolIntString `json:"accepted,omitempty"`
+
+	// A comment from the administrator that processed the request.
+	AdminComment string `json:"adminComment,omitempty"`
+}
+
+// Status returns the state of the request.
+func (r IPRequest) Status() Status {
+	switch {
+	case !bool(r.Processed):
+		return StatusPending
+	case bool(r.Accepted):
+		return StatusApproved
+	}
+	return StatusRejected
+}
+
+// Controller is the base client for the IP requests controller.
+type Controller struct {
+	client.Client
+}
+
+// NewController returns a new instance of the client for the IP requests
+// controller.
+func NewController(sess *session.Session) *Controller {
+	c := &Controller{
+		Client: *client.NewClient(sess),
+	}
+	return c
+}
+
+// ListRequests lists all IP requests.
+func (c *Controller) ListRequests() (out []IPRequest, err error) {
+	return c.ListRequestsWithContext(context.Background())
+}
+
+// ListRequestsWithContext is the same as ListRequests, but takes a
+// context.Context.
+func (c *Controller) ListRequestsWithContext(ctx context.Context) (out []IPRequest, err error) {
+	err = c.SendRequestWithContext(ctx, "GET", "/requests/", &struct{}{}, &out)
+	return
+}
+
+// ListRequestsByStatus lists all IP requests that are in the supplied state.
+func (c *Controller) ListRequestsByStatus(status Status) (out []IPRequest, err error) {
+	return c.ListRequestsByStatusWithContext(context.Background(), status)
+}
+
+// ListRequestsByStatusWithContext is the same as ListRequestsByStatus, but
+// takes a context.Context.
+func (c *Controller) ListRequestsByStatusWithContext(ctx context.Context, status Status) (out []IPRequest, err error) {
+	var all []IPRequest
+	all, err = c.ListRequestsWithContext(ctx)
+	if err != nil {
+		return
+	}
+	for _, r := range all {
+		if r.Status() == status {
+			out = append(out, r)
+		}
+	}
+	return
+}
+
+// GetRequestByID GETs an IP request via its ID.
+func (c *Controller) GetRequestByID(id int) (out IPRequest, err error) {
+	return c.GetRequestByIDWithContext(context.Background(), id)
+}
+
+// GetRequestByIDWithContext is the same as GetRequestByID, but takes a
+// context.Context.
+func (c *Controller) GetRequestByIDWithContext(ctx context.Context, id int) (out IPRequest, err error) {
+	err = c.SendRequestWithContext(ctx, "GET", fmt.Sprintf("/requests/%d/", id), &struct{}{}, &out)
+	return
+}
+
+// SubmitRequest submits a new IP request by sending a POST request, and
+// returns the ID of the new request. The processing fields of in are ignored -
+// new requests are always pending.
+func (c *Controller) SubmitRequest(in IPRequest) (id int, err error) {
+	return c.SubmitRequestWithContext(context.Background(), in)
+}
+
+// SubmitRequestWithContext is the same as SubmitRequest, but takes a
+// context.Context.
+func (c *Controller) SubmitRequestWithContext(ctx context.Context, in IPRequest) (id int, err error) {
+	in.ID = 0
+	in.Processed = false
+	in.Accepted = false
+	in.AdminComment = ""
+	id, err = c.Client.CreateResourceWithContext(ctx, "/requests/", &in)
+	return
+}
+
+// ApproveRequest approves a pending IP request, with an optional comment for
+// the requester. An error is returned if the request has already been
+// processed.
+func (c *Controller) ApproveRequest(id int, comment string) (message string, err error) {
+	return c.ApproveRequestWithContext(context.Background(), id, comment)
+}
+
+// ApproveRequestWithContext is the same as ApproveRequest, but takes a
+// context.Context.
+func (c *Controller) ApproveRequestWithContext(ctx context.Context, id int, comment string) (message string, err error) {
+	message, err = c.transitionRequest(ctx, id, StatusApproved, comment)
+	return
+}
+
+// RejectRequest rejects a pending IP request, with an optional comment for the
+// requester. An error is returned if the request has already been processed.
+func (c *Controller) RejectRequest(id int, comment string) (message string, err error) {
+	return c.RejectRequestWithContext(context.Background(), id, comment)
+}
+
+// RejectRequestWithContext is the same as RejectRequest, but takes a
+// context.Context.
+func (c *Controller) RejectRequestWithContext(ctx context.Context, id int, comment string) (message string, err error) {
+	message, err = c.transitionRequest(ctx, id, StatusRejected, comment)
+	return
+}
+
+// transitionRequest moves the IP request with the supplied ID to state to,
+// after checking that the transition is valid from the request's current
+// state.
+func (c *Controller) transitionRequest(ctx context.Context, id int, to Status, comment string) (message string, err error) {
+	var r IPRequest
+	r, err = c.GetRequestByIDWithContext(ctx, id)
+	if err != nil {
+		return
+	}
+	if from := r.Status(); !from.CanTransitionTo(to) {
+		err = fmt.Errorf("Request %d cannot be %s, as it is already %s", id, to, from)
+		return
+	}
+
+	in := struct {
+		ID           int                   `json:"id,string"`
+		Processed    phpipam.BoolIntString `json:"processed"`
+		Accepted     phpipam.BoolIntString `json:"accepted"`
+		AdminComment string                `json:"adminComment,omitempty"`
+	}{
+		ID:           id,
+		Processed:    true,
+		Accepted:     to == StatusApproved,
+		AdminComment: comment,
+	}
+	err = c.SendRequestWithContext(ctx, "PATCH", "/requests/", &in, &message)
+	return
+}
+
+// DeleteRequest deletes an IP request by its ID.
+func (c *Controller) DeleteRequest(id int) (message string, err error) {
+	return c.DeleteRequestWithContext(context.Background(), id)
+}
+
+// DeleteRequestWithContext is the same as DeleteRequest, but takes a
+// context.Context.
+func (c *Controller) DeleteRequestWithContext(ctx context.Context, id int) (message string, err error) {
+	err = c.SendRequestWithContext(ctx, "DELETE", fmt.Sprintf("/requests/%d/", id), &struct{}{}, &message)
+	return
+}
@@ -0,0 +1,369 @@
+package requests
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/paybyphone/phpipam-sdk-go/phpipam"
+	"github.com/paybyphone/phpipam-sdk-go/phpipam/session"
+)
+
+var testSubmitRequestInput = IPRequest{
+	SubnetID:    3,
+	Hostname:    "host1.example.com",
+	Description: "Web server",
+	Requester:   "jdoe@example.com",
+	Processed:   true,
+	Accepted:    true,
+}
+
+const testSubmitRequestOutputJSON = `
+{
+  "code": 201,
+  "success": true,
+  "message": "Request created",
+  "id": "7",
+  "time": 0.004
+}
+`
+
+const testGetPendingRequestOutputJSON = `
+{
+  "code": 200,
+  "success": true,
+  "data": {
+    "id": "7",
+    "subnetId": "3",
+    "ip_addr": "",
+    "description": "Web server",
+    "hostname": "host1.example.com",
+    "state": "2",
+    "requester": "jdoe@example.com",
+    "comment": null,
+    "processed": "0",
+    "accepted": null,
+    "adminComment": null
+  }
+}
+`
+
+var testGetPendingRequestOutputExpected = IPRequest{
+	ID:          7,
+	SubnetID:    3,
+	Description: "Web server",
+	Hostname:    "host1.example.com",
+	Tag:         2,
+	Requester:   "jdoe@example.com",
+}
+
+const testGetProcessedRequestOutputJSON = `
+{
+  "code": 200,
+  "success": true,
+  "data": {
+    "id": "7",
+    "subnetId": "3",
+    "hostname": "host1.example.com",
+    "processed": "1",
+    "accepted": "0",
+    "adminComment": "No"
+  }
+}
+`
+
+var testListRequestsOutputExpected = []IPRequest{
+	IPRequest{
+		ID:       7,
+		SubnetID: 3,
+		Hostname: "host1.example.com",
+	},
+	IPRequest{
+		ID:        8,
+		SubnetID:  3,
+		IPAddress: "10.10.1.20",
+		Hostname:  "host2.example.com",
+		Processed: true,
+		Accepted:  true,
+	},
+	IPRequest{
+		ID:           9,
+		SubnetID:     3,
+		Hostname:     "host3.example.com",
+		Processed:    true,
+		AdminComment: "No",
+	},
+}
+
+const testListRequestsOutputJSON = `
+{
+  "code": 200,
+  "success": true,
+  "data": [
+    {
+      "id": "7",
+      "subnetId": "3",
+      "hostname": "host1.example.com",
+      "processed": "0"
+    },
+    {
+      "id": "8",
+      "subnetId": "3",
+      "ip_addr": "10.10.1.20",
+      "hostname": "host2.example.com",
+      "processed": "1",
+      "accepted": "1"
+    },
+    {
+      "id": "9",
+      "subnetId": "3",
+      "hostname": "host3.example.com",
+      "processed": "1",
+      "accepted": "0",
+      "adminComment": "No"
+    }
+  ]
+}
+`
+
+const testTransitionRequestOutputExpected = `Request updated`
+const testTransitionRequestOutputJSON = `
+{
+  "code": 200,
+  "success": true,
+  "data": "Request updated"
+}
+`
+
+func newHTTPTestServer(f func(w http.ResponseWriter, r *http.Request)) *httptest.Server {
+	ts := httptest.NewServer(http.HandlerFunc(f))
+	return ts
+}
+
+func httpOKTestServer(output string) *httptest.Server {
+	return newHTTPTestServer(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Content-Type", "application/json")
+		http.Error(w, output, http.StatusOK)
+	})
+}
+
+// httpTransitionTestServer returns a test server that serves get for GETs of
+// request 7, and records the body of any PATCH to the requests controller in
+// body.
+func httpTransitionTestServer(get string, body *map[string]interface{}) *httptest.Server {
+	return newHTTPTestServer(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Content-Type", "application/json")
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/0123456789abcdefgh/requests/7/":
+			http.Error(w, get, http.StatusOK)
+		case r.Method == "PATCH" && r.URL.Path == "/0123456789abcdefgh/requests/":
+			bs, _ := ioutil.ReadAll(r.Body)
+			if err := json.Unmarshal(bs, body); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			http.Error(w, testTransitionRequestOutputJSON, http.StatusOK)
+		default:
+			http.Error(w, "not found", http.StatusNotFound)
+		}
+	})
+}
+
+func fullSessionConfig() *session.Session {
+	return &session.Session{
+		Config: phpipam.Config{
+			AppID:    "0123456789abcdefgh",
+			Password: "changeit",
+			Username: "nobody",
+		},
+		Token: session.Token{
+			String: "foobarbazboop",
+		},
+	}
+}
+
+func TestIPRequestStatus(t *testing.T) {
+	for i, r := range testListRequestsOutputExpected {
+		expected := []Status{StatusPending, StatusApproved, StatusRejected}[i]
+		if actual := r.Status(); expected != actual {
+			t.Fatalf("Request %d: expected %s, got %s", r.ID, expected, actual)
+		}
+	}
+}
+
+func TestStatusCanTransitionTo(t *testing.T) {
+	cases := []struct {
+		from, to Status
+		expected bool
+	}{
+		{StatusPending, StatusApproved, true},
+		{StatusPending, StatusRejected, true},
+		{StatusPending, StatusPending, false},
+		{StatusApproved, StatusRejected, false},
+		{StatusRejected, StatusApproved, false},
+		{StatusApproved, StatusPending, false},
+	}
+	for _, tc := range cases {
+		if actual := tc.from.CanTransitionTo(tc.to); tc.expected != actual {
+			t.Fatalf("%s to %s: expected %t, got %t", tc.from, tc.to, tc.expected, actual)
+		}
+	}
+}
+
+func TestListRequests(t *testing.T) {
+	ts := httpOKTestServer(testListRequestsOutputJSON)
+	defer ts.Close()
+	sess := fullSessionConfig()
+	sess.Config.Endpoint = ts.URL
+	client := NewController(sess)
+
+	expected := testListRequestsOutputExpected
+	actual, err := client.ListRequests()
+	if err != nil {
+		t.Fatalf("Bad: %s", err)
+	}
+
+	if !reflect.DeepEqual(expected, actual) {
+		t.Fatalf("Expected %#v, got %#v", expected, actual)
+	}
+}
+
+func TestListRequestsByStatus(t *testing.T) {
+	ts := httpOKTestServer(testListRequestsOutputJSON)
+	defer ts.Close()
+	sess := fullSessionConfig()
+	sess.Config.Endpoint = ts.URL
+	client := NewController(sess)
+
+	expected := testListRequestsOutputExpected[2:]
+	actual, err := client.ListRequestsByStatus(StatusRejected)
+	if err != nil {
+		t.Fatalf("Bad: %s", err)
+	}
+
+	if !reflect.DeepEqual(expected, actual) {
+		t.Fatalf("Expected %#v, got %#v", expected, actual)
+	}
+}
+
+func TestGetRequestByID(t *testing.T) {
+	ts := httpOKTestServer(testGetPendingRequestOutputJSON)
+	defer ts.Close()
+	sess := fullSessionConfig()
+	sess.Config.Endpoint = ts.URL
+	client := NewController(sess)
+
+	expected := testGetPendingRequestOutputExpected
+	actual, err := client.GetRequestByID(7)
+	if err != nil {
+		t.Fatalf("Bad: %s", err)
+	}
+
+	if !reflect.DeepEqual(expected, actual) {
+		t.Fatalf("Expected %#v, got %#v", expected, actual)
+	}
+}
+
+func TestSubmitRequest(t *testing.T) {
+	var body map[string]interface{}
+	ts := newHTTPTestServer(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Content-Type", "application/json")
+		bs, _ := ioutil.ReadAll(r.Body)
+		if err := json.Unmarshal(bs, &body); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		http.Error(w, testSubmitRequestOutputJSON, http.StatusCreated)
+	})
+	defer ts.Close()
+	sess := fullSessionConfig()
+	sess.Config.Endpoint = ts.URL
+	client := NewController(sess)
+
+	actual, err := client.SubmitRequest(testSubmitRequestInput)
+	if err != nil {
+		t.Fatalf("Bad: %s", err)
+	}
+	if actual != 7 {
+		t.Fatalf("Expected ID 7, got %d", actual)
+	}
+	for _, k := range []string{"processed", "accepted"} {
+		if _, ok := body[k]; ok {
+			t.Fatalf("Expected %s to be omitted from the request, got %#v", k, body)
+		}
+	}
+}
+
+func TestApproveRequest(t *testing.T) {
+	var body map[string]interface{}
+	ts := httpTransitionTestServer(testGetPendingRequestOutputJSON, &body)
+	defer ts.Close()
+	sess := fullSessionConfig()
+	sess.Config.Endpoint = ts.URL
+	client := NewController(sess)
+
+	expected := testTransitionRequestOutputExpected
+	actual, err := client.ApproveRequest(7, "OK")
+	if err != nil {
+		t.Fatalf("Bad: %s", err)
+	}
+	if expected != actual {
+		t.Fatalf("Expected %s, got %s", expected, actual)
+	}
+
+	expectedBody := map[string]interface{}{
+		"id":           "7",
+		"processed":    "1",
+		"accepted":     "1",
+		"adminComment": "OK",
+	}
+	if !reflect.DeepEqual(expectedBody, body) {
+		t.Fatalf("Expected %#v, got %#v", expectedBody, body)
+	}
+}
+
+func TestRejectRequest(t *testing.T) {
+	var body map[string]interface{}
+	ts := httpTransitionTestServer(testGetPendingRequestOutputJSON, &body)
+	defer ts.Close()
+	sess := fullSessionConfig()
+	sess.Config.Endpoint = ts.URL
+	client := NewController(sess)
+
+	if _, err := client.RejectRequest(7, ""); err != nil {
+		t.Fatalf("Bad: %s", err)
+	}
+
+	expectedBody := map[string]interface{}{
+		"id":        "7",
+		"processed": "1",
+		"accepted":  "0",
+	}
+	if !reflect.DeepEqual(expectedBody, body) {
+		t.Fatalf("Expected %#v, got %#v", expectedBody, body)
+	}
+}
+
+func TestApproveRequestAlreadyProcessed(t *testing.T) {
+	var body map[string]interface{}
+	ts := httpTransitionTestServer(testGetProcessedRequestOutputJSON, &body)
+	defer ts.Close()
+	sess := fullSessionConfig()
+	sess.Config.Endpoint = ts.URL
+	client := NewController(sess)
+
+	_, err := client.ApproveRequest(7, "")
+	if err == nil {
+		t.Fatalf("Expected error, got none")
+	}
+	expected := "Request 7 cannot be approved, as it is already rejected"
+	if expected != err.Error() {
+		t.Fatalf("Expected %q, got %q", expected, err.Error())
+	}
+	if body != nil {
+		t.Fatalf("Expected no update to be sent, got %#v", body)
+	}
+}
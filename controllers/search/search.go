@@ -0,0 +1,94 @@
+// Package search provides types and methods for working with the search
+// controller, which searches addresses, subnets and VLANs at once.
+package search
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"github.com/paybyphone/phpipam-sdk-go/controllers/addresses"
+	"github.com/paybyphone/phpipam-sdk-go/controllers/subnets"
+	"github.com/paybyphone/phpipam-sdk-go/controllers/vlans"
+	"github.com/paybyphone/phpipam-sdk-go/phpipam"
+	"github.com/paybyphone/phpipam-sdk-go/phpipam/client"
+	"github.com/paybyphone/phpipam-sdk-go/phpipam/session"
+)
+
+// Result represents the result of a search, grouped by resource kind.
+type Result struct {
+	// The addresses that matched the search term.
+	Addresses []addresses.Address `json:"addresses,omitempty"`
+
+	// The subnets that matched the search term.
+	Subnets []subnets.Subnet `json:"subnets,omitempty"`
+
+	// The VLANs that matched the search term.
+	VLANs []vlans.VLAN `json:"vlans,omitempty"`
+}
+
+// Options restricts the kinds of resources that are searched. If none of the
+// fields are set, all kinds are searched.
+type Options struct {
+	// Search addresses.
+	Addresses bool
+
+	// Search subnets.
+	Subnets bool
+
+	// Search VLANs.
+	VLANs bool
+}
+
+// query returns the query string for the options, or an empty string if all
+// kinds are to be searched.
+func (o Options) query() string {
+	if !o.Addresses && !o.Subnets && !o.VLANs {
+		return ""
+	}
+	v := url.Values{}
+	for k, b := range map[string]bool{
+		"addresses": o.Addresses,
+		"subnets":   o.Subnets,
+		"vlans":     o.VLANs,
+	} {
+		if b {
+			v.Set(k, "1")
+		} else {
+			v.Set(k, "0")
+		}
+	}
+	return "?" + v.Encode()
+}
+
+// Controller is the base client for the Search controller.
+type Controller struct {
+	client.Client
+}
+
+// NewController returns a new instance of the client for the Search
+// controller.
+func NewController(sess *session.Session) *Controller {
+	c := &Controller{
+		Client: *client.NewClient(sess),
+	}
+	return c
+}
+
+// Search searches addresses, subnets and VLANs for the supplied search term.
+// The kinds of resources searched can be restricted with opts - the zero
+// value searches everything.
+//
+// A search that matches nothing returns an empty Result and no error.
+func (c *Controller) Search(term string, opts Options) (out Result, err error) {
+	return c.SearchWithContext(context.Background(), term, opts)
+}
+
+// SearchWithContext is the same as Search, but takes a context.Context.
+func (c *Controller) SearchWithContext(ctx context.Context, term string, opts Options) (out Result, err error) {
+	err = c.SendRequestWithContext(ctx, "GET", fmt.Sprintf("/search/%s/%s", url.PathEscape(term), opts.query()), &struct{}{}, &out)
+	if phpipam.IsNotFound(err) {
+		out, err = Result{}, nil
+	}
+	return
+}
@@ -0,0 +1,242 @@
+package search
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/paybyphone/phpipam-sdk-go/controllers/addresses"
+	"github.com/paybyphone/phpipam-sdk-go/controllers/subnets"
+	"github.com/paybyphone/phpipam-sdk-go/controllers/vlans"
+	"github.com/paybyphone/phpipam-sdk-go/phpipam"
+	"github.com/paybyphone/phpipam-sdk-go/phpipam/session"
+)
+
+var testSearchOutputExpected = Result{
+	Addresses: []addresses.Address{
+		addresses.Address{
+			ID:          11,
+			SubnetID:    3,
+			IPAddress:   "10.10.1.10",
+			Description: "foo",
+		},
+	},
+	Subnets: []subnets.Subnet{
+		subnets.Subnet{
+			ID:            3,
+			SubnetAddress: "10.10.1.0",
+			Mask:          24,
+			Description:   "foo",
+			SectionID:     1,
+		},
+	},
+	VLANs: []vlans.VLAN{
+		vlans.VLAN{
+			ID:       2,
+			DomainID: 1,
+			Name:     "foo",
+			Number:   1000,
+		},
+	},
+}
+
+const testSearchOutputJSON = `
+{
+  "code": 200,
+  "success": true,
+  "data": {
+    "addresses": [
+      {
+        "id": "11",
+        "subnetId": "3",
+        "ip": "10.10.1.10",
+        "description": "foo"
+      }
+    ],
+    "subnets": [
+      {
+        "id": "3",
+        "subnet": "10.10.1.0",
+        "mask": "24",
+        "description": "foo",
+        "sectionId": "1"
+      }
+    ],
+    "vlans": [
+      {
+        "id": "2",
+        "domainId": "1",
+        "name": "foo",
+        "number": "1000"
+      }
+    ]
+  }
+}
+`
+
+var testSearchAddressesOnlyOutputExpected = Result{
+	Addresses: []addresses.Address{
+		addresses.Address{
+			ID:          11,
+			SubnetID:    3,
+			IPAddress:   "10.10.1.10",
+			Description: "foo",
+		},
+	},
+}
+
+const testSearchAddressesOnlyOutputJSON = `
+{
+  "code": 200,
+  "success": true,
+  "data": {
+    "addresses": [
+      {
+        "id": "11",
+        "subnetId": "3",
+        "ip": "10.10.1.10",
+        "description": "foo"
+      }
+    ]
+  }
+}
+`
+
+const testSearchNotFoundOutputJSON = `
+{
+  "code": 404,
+  "success": false,
+  "message": "No results found"
+}
+`
+
+func newHTTPTestServer(f func(w http.ResponseWriter, r *http.Request)) *httptest.Server {
+	ts := httptest.NewServer(http.HandlerFunc(f))
+	return ts
+}
+
+func httpOKTestServer(output string) *httptest.Server {
+	return newHTTPTestServer(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Content-Type", "application/json")
+		http.Error(w, output, http.StatusOK)
+	})
+}
+
+func httpNotFoundTestServer(output string) *httptest.Server {
+	return newHTTPTestServer(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Content-Type", "application/json")
+		http.Error(w, output, http.StatusNotFound)
+	})
+}
+
+func fullSessionConfig() *session.Session {
+	return &session.Session{
+		Config: phpipam.Config{
+			AppID:    "0123456789abcdefgh",
+			Password: "changeit",
+			Username: "nobody",
+		},
+		Token: session.Token{
+			String: "foobarbazboop",
+		},
+	}
+}
+
+func TestSearch(t *testing.T) {
+	ts := newHTTPTestServer(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Content-Type", "application/json")
+		if r.URL.Path != "/0123456789abcdefgh/search/foo/" || r.URL.RawQuery != "" {
+			http.Error(w, "not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, testSearchOutputJSON, http.StatusOK)
+	})
+	defer ts.Close()
+	sess := fullSessionConfig()
+	sess.Config.Endpoint = ts.URL
+	client := NewController(sess)
+
+	expected := testSearchOutputExpected
+	actual, err := client.Search("foo", Options{})
+	if err != nil {
+		t.Fatalf("Bad: %s", err)
+	}
+
+	if !reflect.DeepEqual(expected, actual) {
+		t.Fatalf("Expected %#v, got %#v", expected, actual)
+	}
+}
+
+func TestSearchWithOptions(t *testing.T) {
+	ts := newHTTPTestServer(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Content-Type", "application/json")
+		if r.URL.RawQuery != "addresses=1&subnets=0&vlans=0" {
+			http.Error(w, "bad query "+r.URL.RawQuery, http.StatusBadRequest)
+			return
+		}
+		http.Error(w, testSearchAddressesOnlyOutputJSON, http.StatusOK)
+	})
+	defer ts.Close()
+	sess := fullSessionConfig()
+	sess.Config.Endpoint = ts.URL
+	client := NewController(sess)
+
+	expected := testSearchAddressesOnlyOutputExpected
+	actual, err := client.Search("foo", Options{Addresses: true})
+	if err != nil {
+		t.Fatalf("Bad: %s", err)
+	}
+
+	if !reflect.DeepEqual(expected, actual) {
+		t.Fatalf("Expected %#v, got %#v", expected, actual)
+	}
+}
+
+func TestSearchEscapesTerm(t *testing.T) {
+	cases := map[string]string{
+		"10.10.1.0/24": "/0123456789abcdefgh/search/10.10.1.0%2F24/",
+		"foo?bar#baz":  "/0123456789abcdefgh/search/foo%3Fbar%23baz/",
+	}
+	for term, expected := range cases {
+		var path, query string
+		ts := newHTTPTestServer(func(w http.ResponseWriter, r *http.Request) {
+			path, query = r.URL.EscapedPath(), r.URL.RawQuery
+			w.Header().Add("Content-Type", "application/json")
+			http.Error(w, testSearchAddressesOnlyOutputJSON, http.StatusOK)
+		})
+		sess := fullSessionConfig()
+		sess.Config.Endpoint = ts.URL
+		client := NewController(sess)
+
+		_, err := client.Search(term, Options{Addresses: true})
+		ts.Close()
+		if err != nil {
+			t.Fatalf("%s: Bad: %s", term, err)
+		}
+		if path != expected {
+			t.Fatalf("%s: Expected path %s, got %s", term, expected, path)
+		}
+		if query != "addresses=1&subnets=0&vlans=0" {
+			t.Fatalf("%s: Unexpected query %q", term, query)
+		}
+	}
+}
+
+func TestSearchNoResults(t *testing.T) {
+	ts := httpNotFoundTestServer(testSearchNotFoundOutputJSON)
+	defer ts.Close()
+	sess := fullSessionConfig()
+	sess.Config.Endpoint = ts.URL
+	client := NewController(sess)
+
+	expected := Result{}
+	actual, err := client.Search("foo", Options{})
+	if err != nil {
+		t.Fatalf("Bad: %s", err)
+	}
+
+	if !reflect.DeepEqual(expected, actual) {
+		t.Fatalf("Expected %#v, got %#v", expected, actual)
+	}
+}
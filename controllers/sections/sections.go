@@ -3,6 +3,7 @@
 package sections
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/paybyphone/phpipam-sdk-go/controllers/subnets"
@@ -25,9 +26,9 @@ type Section struct {
 	// The ID of the section's parent, if nested.
 	MasterSection int `json:"masterSection,string,omitempty"`
 
-	// A JSON object, stringified, that represents the permissions for this
-	// section.
-	Permissions string `json:"permissions,omitempty"`
+	// The access level of each group to this section, keyed by group ID. This is
+	// sent to and from the API as a stringified JSON object.
+	Permissions phpipam.Permissions `json:"permissions,omitempty"`
 
 	// Whether or not to check consistency for subnets and IP addresses.
 	StrictMode phpipam.BoolIntString `json:"strictMode,omitempty"`
@@ -71,43 +72,227 @@ func NewController(sess *session.Session) *Controller {
 
 // ListSections lists all sections.
 func (c *Controller) ListSections() (out []Section, err error) {
-	err = c.SendRequest("GET", "/sections/", &struct{}{}, &out)
+	return c.ListSectionsWithContext(context.Background())
+}
+
+// ListSectionsWithContext is the same as ListSections, but takes a
+// context.Context.
+func (c *Controller) ListSectionsWithContext(ctx context.Context) (out []Section, err error) {
+	err = c.SendRequestWithContext(ctx, "GET", "/sections/", &struct{}{}, &out)
 	return
 }
 
 // CreateSection creates a section by sending a POST request.
 func (c *Controller) CreateSection(in Section) (message string, err error) {
-	err = c.SendRequest("POST", "/sections/", &in, &message)
+	return c.CreateSectionWithContext(context.Background(), in)
+}
+
+// CreateSectionWithContext is the same as CreateSection, but takes a
+// context.Context.
+func (c *Controller) CreateSectionWithContext(ctx context.Context, in Section) (message string, err error) {
+	err = c.SendRequestWithContext(ctx, "POST", "/sections/", &in, &message)
+	return
+}
+
+// CreateSectionAndGetID creates a section by sending a POST request, and
+// returns the ID of the new section.
+func (c *Controller) CreateSectionAndGetID(in Section) (id int, err error) {
+	return c.CreateSectionAndGetIDWithContext(context.Background(), in)
+}
+
+// CreateSectionAndGetIDWithContext is the same as CreateSectionAndGetID, but
+// takes a context.Context.
+func (c *Controller) CreateSectionAndGetIDWithContext(ctx context.Context, in Section) (id int, err error) {
+	id, err = c.Client.CreateResourceWithContext(ctx, "/sections/", &in)
+	return
+}
+
+// CreateSectionAndGet creates a section by sending a POST request, and
+// returns the new section as read back from the API.
+func (c *Controller) CreateSectionAndGet(in Section) (out Section, err error) {
+	return c.CreateSectionAndGetWithContext(context.Background(), in)
+}
+
+// CreateSectionAndGetWithContext is the same as CreateSectionAndGet, but takes
+// a context.Context.
+func (c *Controller) CreateSectionAndGetWithContext(ctx context.Context, in Section) (out Section, err error) {
+	var id int
+	id, err = c.CreateSectionAndGetIDWithContext(ctx, in)
+	if err != nil {
+		return
+	}
+	out, err = c.GetSectionByIDWithContext(ctx, id)
 	return
 }
 
 // GetSectionByID GETs a section via its ID.
 func (c *Controller) GetSectionByID(id int) (out Section, err error) {
-	err = c.SendRequest("GET", fmt.Sprintf("/sections/%d/", id), &struct{}{}, &out)
+	return c.GetSectionByIDWithContext(context.Background(), id)
+}
+
+// GetSectionByIDWithContext is the same as GetSectionByID, but takes a
+// context.Context.
+func (c *Controller) GetSectionByIDWithContext(ctx context.Context, id int) (out Section, err error) {
+	err = c.SendRequestWithContext(ctx, "GET", fmt.Sprintf("/sections/%d/", id), &struct{}{}, &out)
 	return
 }
 
 // GetSectionByName GETs a section via its name.
 func (c *Controller) GetSectionByName(name string) (out Section, err error) {
-	err = c.SendRequest("GET", fmt.Sprintf("/sections/%s/", name), &struct{}{}, &out)
+	return c.GetSectionByNameWithContext(context.Background(), name)
+}
+
+// GetSectionByNameWithContext is the same as GetSectionByName, but takes a
+// context.Context.
+func (c *Controller) GetSectionByNameWithContext(ctx context.Context, name string) (out Section, err error) {
+	err = c.SendRequestWithContext(ctx, "GET", fmt.Sprintf("/sections/%s/", name), &struct{}{}, &out)
 	return
 }
 
 // GetSubnetsInSection GETs the subnets in a section by section ID.
 func (c *Controller) GetSubnetsInSection(id int) (out []subnets.Subnet, err error) {
-	err = c.SendRequest("GET", fmt.Sprintf("/sections/%d/subnets/", id), &struct{}{}, &out)
+	return c.GetSubnetsInSectionWithContext(context.Background(), id)
+}
+
+// GetSubnetsInSectionWithContext is the same as GetSubnetsInSection, but takes
+// a context.Context.
+func (c *Controller) GetSubnetsInSectionWithContext(ctx context.Context, id int) (out []subnets.Subnet, err error) {
+	err = c.SendRequestWithContext(ctx, "GET", fmt.Sprintf("/sections/%d/subnets/", id), &struct{}{}, &out)
 	return
 }
 
+// Tree is a section, along with the trees of subnets in it.
+type Tree struct {
+	// The section.
+	Section Section
+
+	// The top-level subnets of the section, with their child subnets.
+	Subnets []*subnets.Node
+}
+
+// Visitor holds the callbacks that are called by Walk. Either callback can be
+// nil.
+type Visitor struct {
+	// Called for each section, before its subnets are visited. If this returns
+	// subnets.SkipChildren, the section's subnets are not visited.
+	Section func(s Section) error
+
+	// Called for each subnet in a section. depth is 0 for top-level subnets of
+	// the section. See subnets.WalkFunc for details on the return value.
+	Subnet func(s Section, n *subnets.Node, depth int) error
+}
+
+// GetTree GETs all sections and their subnets, and returns them as a tree of
+// sections, subnets, and child subnets.
+func (c *Controller) GetTree() (out []Tree, err error) {
+	return c.GetTreeWithContext(context.Background())
+}
+
+// GetTreeWithContext is the same as GetTree, but takes a context.Context.
+func (c *Controller) GetTreeWithContext(ctx context.Context) (out []Tree, err error) {
+	var ss []Section
+	ss, err = c.ListSectionsWithContext(ctx)
+	if err != nil {
+		return
+	}
+	for _, s := range ss {
+		var sn []subnets.Subnet
+		sn, err = c.GetSubnetsInSectionWithContext(ctx, s.ID)
+		// Sections without subnets are returned as not found.
+		if err != nil && !phpipam.IsNotFound(err) {
+			return
+		}
+		err = nil
+		out = append(out, Tree{Section: s, Subnets: subnets.BuildTree(sn)})
+	}
+	return
+}
+
+// GetSectionUsage rolls up the address usage of all subnets in a section, via
+// a supplied section ID. See subnets.Controller.GetTreeUsage for details on
+// how the usage is calculated.
+func (c *Controller) GetSectionUsage(id int) (out subnets.Usage, err error) {
+	return c.GetSectionUsageWithContext(context.Background(), id)
+}
+
+// GetSectionUsageWithContext is the same as GetSectionUsage, but takes a
+// context.Context.
+func (c *Controller) GetSectionUsageWithContext(ctx context.Context, id int) (out subnets.Usage, err error) {
+	var sn []subnets.Subnet
+	sn, err = c.GetSubnetsInSectionWithContext(ctx, id)
+	// Sections without subnets are returned as not found.
+	if err != nil {
+		if phpipam.IsNotFound(err) {
+			err = nil
+		}
+		return
+	}
+	out, err = subnets.NewController(c.Session).GetTreeUsageWithContext(ctx, subnets.BuildTree(sn))
+	return
+}
+
+// Walk GETs the tree of sections and subnets via GetTree, and visits each
+// section and subnet in it in turn, calling the callbacks in v. Subnets are
+// visited depth-first, with parents before their children.
+//
+// The tree is fetched in full before any callbacks are called. A non-nil error
+// from a callback, other than subnets.SkipChildren, stops the walk and is
+// returned.
+func (c *Controller) Walk(v Visitor) error {
+	return c.WalkWithContext(context.Background(), v)
+}
+
+// WalkWithContext is the same as Walk, but takes a context.Context.
+func (c *Controller) WalkWithContext(ctx context.Context, v Visitor) error {
+	trees, err := c.GetTreeWithContext(ctx)
+	if err != nil {
+		return err
+	}
+	for _, t := range trees {
+		if v.Section != nil {
+			switch err := v.Section(t.Section); err {
+			case nil:
+			case subnets.SkipChildren:
+				continue
+			default:
+				return err
+			}
+		}
+		if v.Subnet == nil {
+			continue
+		}
+		s := t.Section
+		fn := func(n *subnets.Node, depth int) error {
+			return v.Subnet(s, n, depth)
+		}
+		if err := subnets.WalkTree(t.Subnets, fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // UpdateSection updates a section by sending a PATCH request.
 func (c *Controller) UpdateSection(in Section) (err error) {
-	err = c.SendRequest("PATCH", "/sections/", &in, &struct{}{})
+	return c.UpdateSectionWithContext(context.Background(), in)
+}
+
+// UpdateSectionWithContext is the same as UpdateSection, but takes a
+// context.Context.
+func (c *Controller) UpdateSectionWithContext(ctx context.Context, in Section) (err error) {
+	err = c.SendRequestWithContext(ctx, "PATCH", "/sections/", &in, &struct{}{})
 	return
 }
 
 // DeleteSection deletes a section by sending a DELETE request. All subnets and
 // addresses in the section will be deleted as well.
 func (c *Controller) DeleteSection(id int) (err error) {
-	err = c.SendRequest("DELETE", fmt.Sprintf("/sections/%d/", id), &struct{}{}, &struct{}{})
+	return c.DeleteSectionWithContext(context.Background(), id)
+}
+
+// DeleteSectionWithContext is the same as DeleteSection, but takes a
+// context.Context.
+func (c *Controller) DeleteSectionWithContext(ctx context.Context, id int) (err error) {
+	err = c.SendRequestWithContext(ctx, "DELETE", fmt.Sprintf("/sections/%d/", id), &struct{}{}, &struct{}{})
 	return
 }
@@ -1,6 +1,7 @@
 package sections
 
 import (
+	"fmt"
 	"log"
 	"net/http"
 	"net/http/httptest"
@@ -20,7 +21,7 @@ var testListSectionsOutputExpected = []Section{
 		ID:          2,
 		Name:        "IPv6",
 		Description: "Section for IPv6 addresses",
-		Permissions: "{\"3\":\"1\",\"2\":\"2\"}",
+		Permissions: phpipam.Permissions{3: phpipam.AccessRead, 2: phpipam.AccessWrite},
 	},
 	Section{
 		ID:   3,
@@ -30,7 +31,7 @@ var testListSectionsOutputExpected = []Section{
 		ID:          1,
 		Name:        "Customers",
 		Description: "Section for customers",
-		Permissions: "{\"3\":\"1\",\"2\":\"2\"}",
+		Permissions: phpipam.Permissions{3: phpipam.AccessRead, 2: phpipam.AccessWrite},
 	},
 }
 
@@ -106,7 +107,7 @@ const testListSectionsOutputJSON = `
 var testCreateSectionInput = Section{
 	Name:        "foobar",
 	StrictMode:  true,
-	Permissions: "{\"3\":\"1\",\"2\":\"2\"}",
+	Permissions: phpipam.Permissions{3: phpipam.AccessRead, 2: phpipam.AccessWrite},
 }
 
 const testCreateSectionOutputExpected = `Section created`
@@ -122,7 +123,7 @@ var testGetSectionOutputExpected = Section{
 	ID:          1,
 	Name:        "Customers",
 	Description: "Section for customers",
-	Permissions: "{\"3\":\"1\",\"2\":\"2\"}",
+	Permissions: phpipam.Permissions{3: phpipam.AccessRead, 2: phpipam.AccessWrite},
 }
 
 const testGetSectionOutputJSON = `
@@ -339,7 +340,7 @@ var testGetSubnetsInSectionExpected = []subnets.Subnet{
 		AllowRequests:  false,
 		Description:    "My folder",
 		ShowName:       false,
-		Permissions:    "{\"3\":\"1\",\"2\":\"2\"}",
+		Permissions:    phpipam.Permissions{3: phpipam.AccessRead, 2: phpipam.AccessWrite},
 		IsFolder:       true,
 	},
 	subnets.Subnet{
@@ -351,7 +352,7 @@ var testGetSubnetsInSectionExpected = []subnets.Subnet{
 		AllowRequests:  true,
 		Description:    "Business customers",
 		ShowName:       true,
-		Permissions:    "{\"3\":\"1\",\"2\":\"2\"}",
+		Permissions:    phpipam.Permissions{3: phpipam.AccessRead, 2: phpipam.AccessWrite},
 	},
 	subnets.Subnet{
 		ID:             3,
@@ -362,7 +363,7 @@ var testGetSubnetsInSectionExpected = []subnets.Subnet{
 		AllowRequests:  true,
 		Description:    "Customer 1",
 		ShowName:       true,
-		Permissions:    "{\"3\":\"1\",\"2\":\"2\"}",
+		Permissions:    phpipam.Permissions{3: phpipam.AccessRead, 2: phpipam.AccessWrite},
 	},
 	subnets.Subnet{
 		ID:             4,
@@ -373,7 +374,7 @@ var testGetSubnetsInSectionExpected = []subnets.Subnet{
 		AllowRequests:  true,
 		Description:    "Customer 2",
 		ShowName:       true,
-		Permissions:    "{\"3\":\"1\",\"2\":\"2\"}",
+		Permissions:    phpipam.Permissions{3: phpipam.AccessRead, 2: phpipam.AccessWrite},
 	},
 	subnets.Subnet{
 		ID:             6,
@@ -384,7 +385,7 @@ var testGetSubnetsInSectionExpected = []subnets.Subnet{
 		AllowRequests:  false,
 		Description:    "DHCP range",
 		ShowName:       true,
-		Permissions:    "{\"3\":\"1\",\"2\":\"2\"}",
+		Permissions:    phpipam.Permissions{3: phpipam.AccessRead, 2: phpipam.AccessWrite},
 	},
 }
 
@@ -411,6 +412,37 @@ const testDeleteSectionOutputJSON = `
 }
 `
 
+const testCreateSectionAndGetIDOutputJSON = `
+{
+  "code": 201,
+  "success": true,
+  "message": "Section created",
+  "id": "3",
+  "time": 0.012
+}
+`
+
+const testGetSubnetUsageOutputJSON = `
+{
+  "code": 200,
+  "success": true,
+  "data": {
+    "used": "3",
+    "maxhosts": "254",
+    "freehosts": "251",
+    "freehosts_percent": 98.82
+  }
+}
+`
+
+const testNoSubnetsOutputJSON = `
+{
+  "code": 404,
+  "success": false,
+  "message": "No subnets found"
+}
+`
+
 func newHTTPTestServer(f func(w http.ResponseWriter, r *http.Request)) *httptest.Server {
 	ts := httptest.NewServer(http.HandlerFunc(f))
 	return ts
@@ -430,6 +462,22 @@ func httpCreatedTestServer(output string) *httptest.Server {
 	})
 }
 
+// httpTreeTestServer returns a test server that serves the section list, and
+// the subnets of section 1. The other sections have no subnets.
+func httpTreeTestServer() *httptest.Server {
+	return newHTTPTestServer(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/0123456789abcdefgh/sections/":
+			http.Error(w, testListSectionsOutputJSON, http.StatusOK)
+		case "/0123456789abcdefgh/sections/1/subnets/":
+			http.Error(w, testGetSubnetsInSectionOutputJSON, http.StatusOK)
+		default:
+			http.Error(w, testNoSubnetsOutputJSON, http.StatusNotFound)
+		}
+	})
+}
+
 func fullSessionConfig() *session.Session {
 	return &session.Session{
 		Config: phpipam.Config{
@@ -480,6 +528,38 @@ func TestCreateSection(t *testing.T) {
 	}
 }
 
+func TestCreateSectionAndGetID(t *testing.T) {
+	ts := httpCreatedTestServer(testCreateSectionAndGetIDOutputJSON)
+	defer ts.Close()
+	sess := fullSessionConfig()
+	sess.Config.Endpoint = ts.URL
+	client := NewController(sess)
+
+	in := testCreateSectionInput
+	expected := 3
+	actual, err := client.CreateSectionAndGetID(in)
+	if err != nil {
+		t.Fatalf("Bad: %s", err)
+	}
+
+	if expected != actual {
+		t.Fatalf("Expected %#v, got %#v", expected, actual)
+	}
+}
+
+func TestCreateSectionAndGetIDMissingID(t *testing.T) {
+	ts := httpCreatedTestServer(testCreateSectionOutputJSON)
+	defer ts.Close()
+	sess := fullSessionConfig()
+	sess.Config.Endpoint = ts.URL
+	client := NewController(sess)
+
+	in := testCreateSectionInput
+	if _, err := client.CreateSectionAndGetID(in); err == nil {
+		t.Fatalf("Expected error, got none")
+	}
+}
+
 func TestGetSectionByID(t *testing.T) {
 	ts := httpOKTestServer(testGetSectionOutputJSON)
 	defer ts.Close()
@@ -534,6 +614,140 @@ func TestGetSubnetsInSection(t *testing.T) {
 	}
 }
 
+func TestGetTree(t *testing.T) {
+	ts := httpTreeTestServer()
+	defer ts.Close()
+	sess := fullSessionConfig()
+	sess.Config.Endpoint = ts.URL
+	client := NewController(sess)
+
+	actual, err := client.GetTree()
+	if err != nil {
+		t.Fatalf("Bad: %s", err)
+	}
+
+	if len(actual) != 3 {
+		t.Fatalf("Expected 3 sections, got %d", len(actual))
+	}
+	for i, s := range testListSectionsOutputExpected {
+		if !reflect.DeepEqual(s, actual[i].Section) {
+			t.Fatalf("Expected %#v, got %#v", s, actual[i].Section)
+		}
+	}
+	if len(actual[0].Subnets) != 0 || len(actual[1].Subnets) != 0 {
+		t.Fatalf("Expected no subnets in sections 2 and 3, got %#v", actual[:2])
+	}
+	if len(actual[2].Subnets) != 2 {
+		t.Fatalf("Expected 2 top-level subnets in section 1, got %d", len(actual[2].Subnets))
+	}
+}
+
+func TestWalk(t *testing.T) {
+	ts := httpTreeTestServer()
+	defer ts.Close()
+	sess := fullSessionConfig()
+	sess.Config.Endpoint = ts.URL
+	client := NewController(sess)
+
+	var visited []string
+	err := client.Walk(Visitor{
+		Section: func(s Section) error {
+			visited = append(visited, s.Name)
+			return nil
+		},
+		Subnet: func(s Section, n *subnets.Node, depth int) error {
+			visited = append(visited, fmt.Sprintf("%s:%d@%d", s.Name, n.Subnet.ID, depth))
+			return nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("Bad: %s", err)
+	}
+
+	expected := []string{
+		"IPv6",
+		"foobar",
+		"Customers",
+		"Customers:5@0",
+		"Customers:6@1",
+		"Customers:2@0",
+		"Customers:3@1",
+		"Customers:4@1",
+	}
+	if !reflect.DeepEqual(expected, visited) {
+		t.Fatalf("Expected %v, got %v", expected, visited)
+	}
+}
+
+func TestWalkSkipSection(t *testing.T) {
+	ts := httpTreeTestServer()
+	defer ts.Close()
+	sess := fullSessionConfig()
+	sess.Config.Endpoint = ts.URL
+	client := NewController(sess)
+
+	var visited int
+	err := client.Walk(Visitor{
+		Section: func(s Section) error {
+			return subnets.SkipChildren
+		},
+		Subnet: func(s Section, n *subnets.Node, depth int) error {
+			visited++
+			return nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("Bad: %s", err)
+	}
+	if visited != 0 {
+		t.Fatalf("Expected no subnets to be visited, visited %d", visited)
+	}
+}
+
+func TestGetSectionUsage(t *testing.T) {
+	ts := newHTTPTestServer(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/0123456789abcdefgh/sections/1/subnets/":
+			http.Error(w, testGetSubnetsInSectionOutputJSON, http.StatusOK)
+		// Subnet 5 is a folder, so the usage of its child subnet 6 is used
+		// instead.
+		case "/0123456789abcdefgh/subnets/2/usage/", "/0123456789abcdefgh/subnets/6/usage/":
+			http.Error(w, testGetSubnetUsageOutputJSON, http.StatusOK)
+		default:
+			http.Error(w, "not found", http.StatusNotFound)
+		}
+	})
+	defer ts.Close()
+	sess := fullSessionConfig()
+	sess.Config.Endpoint = ts.URL
+	client := NewController(sess)
+
+	actual, err := client.GetSectionUsage(1)
+	if err != nil {
+		t.Fatalf("Bad: %s", err)
+	}
+	if actual.Used != 6 || actual.MaxHosts != 508 || actual.FreeHosts != 502 {
+		t.Fatalf("Unexpected usage: %#v", actual)
+	}
+}
+
+func TestGetSectionUsageNoSubnets(t *testing.T) {
+	ts := httpTreeTestServer()
+	defer ts.Close()
+	sess := fullSessionConfig()
+	sess.Config.Endpoint = ts.URL
+	client := NewController(sess)
+
+	actual, err := client.GetSectionUsage(3)
+	if err != nil {
+		t.Fatalf("Bad: %s", err)
+	}
+	if !reflect.DeepEqual(subnets.Usage{}, actual) {
+		t.Fatalf("Expected zero usage, got %#v", actual)
+	}
+}
+
 func TestUpdateSection(t *testing.T) {
 	ts := httpOKTestServer(testUpdateSectionOutputJSON)
 	defer ts.Close()
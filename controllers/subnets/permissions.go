@@ -0,0 +1,116 @@
+package subnets
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/paybyphone/phpipam-sdk-go/phpipam"
+)
+
+// SetSubnetPermissions replaces the group permissions of a subnet with p.
+// Groups that currently have access to the subnet but are not in p have their
+// access removed. If recursive is true, the permissions of all child subnets
+// are replaced as well.
+func (c *Controller) SetSubnetPermissions(id int, p phpipam.Permissions, recursive bool) (err error) {
+	return c.SetSubnetPermissionsWithContext(context.Background(), id, p, recursive)
+}
+
+// SetSubnetPermissionsWithContext is the same as SetSubnetPermissions, but
+// takes a context.Context.
+func (c *Controller) SetSubnetPermissionsWithContext(ctx context.Context, id int, p phpipam.Permissions, recursive bool) (err error) {
+	err = c.updatePermissions(ctx, id, recursive, func(s Subnet) phpipam.Permissions {
+		out := make(phpipam.Permissions, len(s.Permissions)+len(p))
+		for g := range s.Permissions {
+			out[g] = phpipam.AccessNone
+		}
+		for g, a := range p {
+			out[g] = a
+		}
+		return out
+	})
+	return
+}
+
+// AddSubnetPermissions grants the groups in p access to a subnet, leaving the
+// access of other groups unchanged. Groups in p that already have access have
+// their access level updated. If recursive is true, the permissions are added
+// to all child subnets as well.
+func (c *Controller) AddSubnetPermissions(id int, p phpipam.Permissions, recursive bool) (err error) {
+	return c.AddSubnetPermissionsWithContext(context.Background(), id, p, recursive)
+}
+
+// AddSubnetPermissionsWithContext is the same as AddSubnetPermissions, but
+// takes a context.Context.
+func (c *Controller) AddSubnetPermissionsWithContext(ctx context.Context, id int, p phpipam.Permissions, recursive bool) (err error) {
+	err = c.updatePermissions(ctx, id, recursive, func(s Subnet) phpipam.Permissions {
+		out := make(phpipam.Permissions, len(s.Permissions)+len(p))
+		for g, a := range s.Permissions {
+			out[g] = a
+		}
+		for g, a := range p {
+			out[g] = a
+		}
+		return out
+	})
+	return
+}
+
+// RemoveSubnetPermissions removes the access of the supplied groups to a
+// subnet. If recursive is true, their access is removed from all child
+// subnets as well.
+func (c *Controller) RemoveSubnetPermissions(id int, groups []int, recursive bool) (err error) {
+	return c.RemoveSubnetPermissionsWithContext(context.Background(), id, groups, recursive)
+}
+
+// RemoveSubnetPermissionsWithContext is the same as RemoveSubnetPermissions,
+// but takes a context.Context.
+func (c *Controller) RemoveSubnetPermissionsWithContext(ctx context.Context, id int, groups []int, recursive bool) (err error) {
+	err = c.updatePermissions(ctx, id, recursive, func(s Subnet) phpipam.Permissions {
+		out := make(phpipam.Permissions, len(s.Permissions)+len(groups))
+		for g, a := range s.Permissions {
+			out[g] = a
+		}
+		for _, g := range groups {
+			out[g] = phpipam.AccessNone
+		}
+		return out
+	})
+	return
+}
+
+// updatePermissions PATCHes the permissions of the subnet with the supplied ID,
+// and of its child subnets if recursive is true. The full set of permissions
+// for each subnet is computed by fn from the subnet's current state, as the
+// API replaces the permissions of a subnet outright. Subnets are updated
+// parents first, and the first error stops the update.
+func (c *Controller) updatePermissions(ctx context.Context, id int, recursive bool, fn func(Subnet) phpipam.Permissions) error {
+	root, err := c.GetSubnetByIDWithContext(ctx, id)
+	if err != nil {
+		return err
+	}
+	targets := []Subnet{root}
+	if recursive {
+		slaves, err := c.GetSlavesRecursiveWithContext(ctx, id)
+		// Subnets without slaves are returned as not found.
+		if err != nil && !phpipam.IsNotFound(err) {
+			return err
+		}
+		for _, s := range slaves {
+			if s.ID != id {
+				targets = append(targets, s)
+			}
+		}
+	}
+
+	for _, s := range targets {
+		in := fn(s).Groups()
+		if len(in) == 0 {
+			continue
+		}
+		var message string
+		if err := c.SendRequestWithContext(ctx, "PATCH", fmt.Sprintf("/subnets/%d/permissions/", s.ID), &in, &message); err != nil {
+			return fmt.Errorf("Error updating permissions of subnet %d: %w", s.ID, err)
+		}
+	}
+	return nil
+}
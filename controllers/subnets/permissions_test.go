@@ -0,0 +1,177 @@
+package subnets
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/paybyphone/phpipam-sdk-go/phpipam"
+)
+
+const testPermissionsSubnetOutputJSON = `
+{
+  "code": 200,
+  "success": true,
+  "data": {
+    "id": "8",
+    "subnet": "10.10.0.0",
+    "mask": "16",
+    "sectionId": "1",
+    "permissions": "{\"2\":\"2\",\"3\":\"1\"}"
+  }
+}
+`
+
+const testPermissionsSlavesOutputJSON = `
+{
+  "code": 200,
+  "success": true,
+  "data": [
+    {
+      "id": "8",
+      "subnet": "10.10.0.0",
+      "mask": "16",
+      "sectionId": "1",
+      "permissions": "{\"2\":\"2\",\"3\":\"1\"}"
+    },
+    {
+      "id": "9",
+      "subnet": "10.10.1.0",
+      "mask": "24",
+      "sectionId": "1",
+      "masterSubnetId": "8",
+      "permissions": "{\"3\":\"1\",\"4\":\"3\"}"
+    }
+  ]
+}
+`
+
+const testPermissionsUpdateOutputJSON = `
+{
+  "code": 200,
+  "success": true,
+  "data": "Subnet permissions updated"
+}
+`
+
+// httpPermissionsTestServer returns a test server for the subnet permission
+// functions, serving subnet 8 and its child subnet 9. The body of each
+// permissions PATCH is stored in sent, keyed by subnet path.
+func httpPermissionsTestServer(sent map[string]map[string]string) *httptest.Server {
+	return newHTTPTestServer(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Content-Type", "application/json")
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/0123456789abcdefgh/subnets/8/":
+			http.Error(w, testPermissionsSubnetOutputJSON, http.StatusOK)
+		case r.Method == "GET" && r.URL.Path == "/0123456789abcdefgh/subnets/8/slaves_recursive/":
+			http.Error(w, testPermissionsSlavesOutputJSON, http.StatusOK)
+		case r.Method == "PATCH":
+			var in map[string]string
+			if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			sent[r.URL.Path] = in
+			http.Error(w, testPermissionsUpdateOutputJSON, http.StatusOK)
+		default:
+			http.Error(w, "not found", http.StatusNotFound)
+		}
+	})
+}
+
+func TestSetSubnetPermissions(t *testing.T) {
+	sent := make(map[string]map[string]string)
+	ts := httpPermissionsTestServer(sent)
+	defer ts.Close()
+	sess := fullSessionConfig()
+	sess.Config.Endpoint = ts.URL
+	client := NewController(sess)
+
+	err := client.SetSubnetPermissions(8, phpipam.Permissions{3: phpipam.AccessWrite, 5: phpipam.AccessRead}, false)
+	if err != nil {
+		t.Fatalf("Bad: %s", err)
+	}
+	expected := map[string]map[string]string{
+		"/0123456789abcdefgh/subnets/8/permissions/": {"2": "0", "3": "2", "5": "1"},
+	}
+	if !reflect.DeepEqual(expected, sent) {
+		t.Fatalf("Expected %#v, got %#v", expected, sent)
+	}
+}
+
+func TestSetSubnetPermissionsRecursive(t *testing.T) {
+	sent := make(map[string]map[string]string)
+	ts := httpPermissionsTestServer(sent)
+	defer ts.Close()
+	sess := fullSessionConfig()
+	sess.Config.Endpoint = ts.URL
+	client := NewController(sess)
+
+	err := client.SetSubnetPermissions(8, phpipam.Permissions{3: phpipam.AccessWrite}, true)
+	if err != nil {
+		t.Fatalf("Bad: %s", err)
+	}
+	expected := map[string]map[string]string{
+		"/0123456789abcdefgh/subnets/8/permissions/": {"2": "0", "3": "2"},
+		"/0123456789abcdefgh/subnets/9/permissions/": {"3": "2", "4": "0"},
+	}
+	if !reflect.DeepEqual(expected, sent) {
+		t.Fatalf("Expected %#v, got %#v", expected, sent)
+	}
+}
+
+func TestAddSubnetPermissions(t *testing.T) {
+	sent := make(map[string]map[string]string)
+	ts := httpPermissionsTestServer(sent)
+	defer ts.Close()
+	sess := fullSessionConfig()
+	sess.Config.Endpoint = ts.URL
+	client := NewController(sess)
+
+	err := client.AddSubnetPermissions(8, phpipam.Permissions{5: phpipam.AccessAdmin}, true)
+	if err != nil {
+		t.Fatalf("Bad: %s", err)
+	}
+	expected := map[string]map[string]string{
+		"/0123456789abcdefgh/subnets/8/permissions/": {"2": "2", "3": "1", "5": "3"},
+		"/0123456789abcdefgh/subnets/9/permissions/": {"3": "1", "4": "3", "5": "3"},
+	}
+	if !reflect.DeepEqual(expected, sent) {
+		t.Fatalf("Expected %#v, got %#v", expected, sent)
+	}
+}
+
+func TestRemoveSubnetPermissions(t *testing.T) {
+	sent := make(map[string]map[string]string)
+	ts := httpPermissionsTestServer(sent)
+	defer ts.Close()
+	sess := fullSessionConfig()
+	sess.Config.Endpoint = ts.URL
+	client := NewController(sess)
+
+	err := client.RemoveSubnetPermissions(8, []int{3}, false)
+	if err != nil {
+		t.Fatalf("Bad: %s", err)
+	}
+	expected := map[string]map[string]string{
+		"/0123456789abcdefgh/subnets/8/permissions/": {"2": "2", "3": "0"},
+	}
+	if !reflect.DeepEqual(expected, sent) {
+		t.Fatalf("Expected %#v, got %#v", expected, sent)
+	}
+}
+
+func TestRemoveSubnetPermissionsError(t *testing.T) {
+	ts := httpOKTestServer(testPermissionsSubnetOutputJSON)
+	defer ts.Close()
+	sess := fullSessionConfig()
+	sess.Config.Endpoint = ts.URL
+	client := NewController(sess)
+
+	ts.Close()
+	if err := client.RemoveSubnetPermissions(8, []int{3}, false); err == nil {
+		t.Fatalf("Expected error, got none")
+	}
+}
@@ -3,7 +3,10 @@
 package subnets
 
 import (
+	"context"
 	"fmt"
+	"net"
+	"strings"
 
 	"github.com/paybyphone/phpipam-sdk-go/controllers/addresses"
 	"github.com/paybyphone/phpipam-sdk-go/phpipam"
@@ -47,9 +50,9 @@ type Subnet struct {
 	// address.
 	ShowName phpipam.BoolIntString `json:"showName,omitempty"`
 
-	// A JSON object, stringified, that represents the permissions for this
-	// section.
-	Permissions string `json:"permissions,omitempty"`
+	// The access level of each group to this subnet, keyed by group ID. This is
+	// sent to and from the API as a stringified JSON object.
+	Permissions phpipam.Permissions `json:"permissions,omitempty"`
 
 	// Controls if PTR records should be created for the subnet.
 	DNSRecursive phpipam.BoolIntString `json:"DNSrecursive,omitempty"`
@@ -107,13 +110,56 @@ func NewController(sess *session.Session) *Controller {
 
 // CreateSubnet creates a subnet by sending a POST request.
 func (c *Controller) CreateSubnet(in Subnet) (message string, err error) {
-	err = c.SendRequest("POST", "/subnets/", &in, &message)
+	return c.CreateSubnetWithContext(context.Background(), in)
+}
+
+// CreateSubnetWithContext is the same as CreateSubnet, but takes a
+// context.Context.
+func (c *Controller) CreateSubnetWithContext(ctx context.Context, in Subnet) (message string, err error) {
+	err = c.SendRequestWithContext(ctx, "POST", "/subnets/", &in, &message)
+	return
+}
+
+// CreateSubnetAndGetID creates a subnet by sending a POST request, and
+// returns the ID of the new subnet.
+func (c *Controller) CreateSubnetAndGetID(in Subnet) (id int, err error) {
+	return c.CreateSubnetAndGetIDWithContext(context.Background(), in)
+}
+
+// CreateSubnetAndGetIDWithContext is the same as CreateSubnetAndGetID, but
+// takes a context.Context.
+func (c *Controller) CreateSubnetAndGetIDWithContext(ctx context.Context, in Subnet) (id int, err error) {
+	id, err = c.Client.CreateResourceWithContext(ctx, "/subnets/", &in)
+	return
+}
+
+// CreateSubnetAndGet creates a subnet by sending a POST request, and
+// returns the new subnet as read back from the API.
+func (c *Controller) CreateSubnetAndGet(in Subnet) (out Subnet, err error) {
+	return c.CreateSubnetAndGetWithContext(context.Background(), in)
+}
+
+// CreateSubnetAndGetWithContext is the same as CreateSubnetAndGet, but takes a
+// context.Context.
+func (c *Controller) CreateSubnetAndGetWithContext(ctx context.Context, in Subnet) (out Subnet, err error) {
+	var id int
+	id, err = c.CreateSubnetAndGetIDWithContext(ctx, in)
+	if err != nil {
+		return
+	}
+	out, err = c.GetSubnetByIDWithContext(ctx, id)
 	return
 }
 
 // GetSubnetByID GETs a subnet via its ID.
 func (c *Controller) GetSubnetByID(id int) (out Subnet, err error) {
-	err = c.SendRequest("GET", fmt.Sprintf("/subnets/%d/", id), &struct{}{}, &out)
+	return c.GetSubnetByIDWithContext(context.Background(), id)
+}
+
+// GetSubnetByIDWithContext is the same as GetSubnetByID, but takes a
+// context.Context.
+func (c *Controller) GetSubnetByIDWithContext(ctx context.Context, id int) (out Subnet, err error) {
+	err = c.SendRequestWithContext(ctx, "GET", fmt.Sprintf("/subnets/%d/", id), &struct{}{}, &out)
 	return
 }
 
@@ -125,7 +171,72 @@ func (c *Controller) GetSubnetByID(id int) (out Subnet, err error) {
 // will not return multiple results, and using the CIDR of a master subnet will
 // return that subnet only.
 func (c *Controller) GetSubnetsByCIDR(cidr string) (out []Subnet, err error) {
-	err = c.SendRequest("GET", fmt.Sprintf("/subnets/cidr/%s/", cidr), &struct{}{}, &out)
+	return c.GetSubnetsByCIDRWithContext(context.Background(), cidr)
+}
+
+// GetSubnetsByCIDRWithContext is the same as GetSubnetsByCIDR, but takes a
+// context.Context.
+func (c *Controller) GetSubnetsByCIDRWithContext(ctx context.Context, cidr string) (out []Subnet, err error) {
+	err = c.SendRequestWithContext(ctx, "GET", fmt.Sprintf("/subnets/cidr/%s/", cidr), &struct{}{}, &out)
+	return
+}
+
+// GetSlaves GETs the immediate child subnets (slaves) of a subnet, via a
+// supplied subnet ID.
+func (c *Controller) GetSlaves(id int) (out []Subnet, err error) {
+	return c.GetSlavesWithContext(context.Background(), id)
+}
+
+// GetSlavesWithContext is the same as GetSlaves, but takes a context.Context.
+func (c *Controller) GetSlavesWithContext(ctx context.Context, id int) (out []Subnet, err error) {
+	err = c.SendRequestWithContext(ctx, "GET", fmt.Sprintf("/subnets/%d/slaves/", id), &struct{}{}, &out)
+	return
+}
+
+// GetSlavesRecursive GETs all of the descendant subnets of a subnet, via a
+// supplied subnet ID. The result is a flat list - use BuildTree or
+// GetSubnetTree to arrange it by parent.
+func (c *Controller) GetSlavesRecursive(id int) (out []Subnet, err error) {
+	return c.GetSlavesRecursiveWithContext(context.Background(), id)
+}
+
+// GetSlavesRecursiveWithContext is the same as GetSlavesRecursive, but takes a
+// context.Context.
+func (c *Controller) GetSlavesRecursiveWithContext(ctx context.Context, id int) (out []Subnet, err error) {
+	err = c.SendRequestWithContext(ctx, "GET", fmt.Sprintf("/subnets/%d/slaves_recursive/", id), &struct{}{}, &out)
+	return
+}
+
+// GetSubnetTree GETs a subnet and all of its descendants, via a supplied
+// subnet ID, and returns them as a tree rooted at the subnet.
+func (c *Controller) GetSubnetTree(id int) (out *Node, err error) {
+	return c.GetSubnetTreeWithContext(context.Background(), id)
+}
+
+// GetSubnetTreeWithContext is the same as GetSubnetTree, but takes a
+// context.Context.
+func (c *Controller) GetSubnetTreeWithContext(ctx context.Context, id int) (out *Node, err error) {
+	var root Subnet
+	root, err = c.GetSubnetByIDWithContext(ctx, id)
+	if err != nil {
+		return
+	}
+	var slaves []Subnet
+	slaves, err = c.GetSlavesRecursiveWithContext(ctx, id)
+	if err != nil && !phpipam.IsNotFound(err) {
+		return
+	}
+	err = nil
+
+	// Depending on the PHPIPAM version, the subnet itself may be included in
+	// its list of slaves.
+	in := []Subnet{root}
+	for _, s := range slaves {
+		if s.ID != id {
+			in = append(in, s)
+		}
+	}
+	out = BuildTree(in)[0]
 	return
 }
 
@@ -135,52 +246,283 @@ func (c *Controller) GetSubnetsByCIDR(cidr string) (out []Subnet, err error) {
 // blank.
 //
 // Note that marking a subnet as used does not prevent this function from
-// returning data.
+// returning data. This does not reserve the address either - use
+// addresses.Controller.CreateFirstFreeAddress to allocate one atomically.
 func (c *Controller) GetFirstFreeAddress(id int) (out string, err error) {
-	err = c.SendRequest("GET", fmt.Sprintf("/subnets/%d/first_free/", id), &struct{}{}, &out)
+	return c.GetFirstFreeAddressWithContext(context.Background(), id)
+}
+
+// GetFirstFreeAddressWithContext is the same as GetFirstFreeAddress, but takes
+// a context.Context.
+func (c *Controller) GetFirstFreeAddressWithContext(ctx context.Context, id int) (out string, err error) {
+	err = c.SendRequestWithContext(ctx, "GET", fmt.Sprintf("/subnets/%d/first_free/", id), &struct{}{}, &out)
+	return
+}
+
+// GetFirstFreeSubnet GETs the first free child subnet with the supplied mask
+// in a master subnet, and returns it in CIDR notation (i.e. "10.10.1.0/24"). If
+// there is no room left for a subnet of that size, the API returns an error.
+//
+// This does not reserve the subnet - use CreateFirstFreeSubnet for that.
+func (c *Controller) GetFirstFreeSubnet(id, mask int) (out string, err error) {
+	return c.GetFirstFreeSubnetWithContext(context.Background(), id, mask)
+}
+
+// GetFirstFreeSubnetWithContext is the same as GetFirstFreeSubnet, but takes a
+// context.Context.
+func (c *Controller) GetFirstFreeSubnetWithContext(ctx context.Context, id, mask int) (out string, err error) {
+	err = c.SendRequestWithContext(ctx, "GET", fmt.Sprintf("/subnets/%d/first_subnet/%d/", id, mask), &struct{}{}, &out)
+	return
+}
+
+// GetAllFreeSubnets GETs all free child subnets with the supplied mask in a
+// master subnet, and returns them in CIDR notation.
+func (c *Controller) GetAllFreeSubnets(id, mask int) (out []string, err error) {
+	return c.GetAllFreeSubnetsWithContext(context.Background(), id, mask)
+}
+
+// GetAllFreeSubnetsWithContext is the same as GetAllFreeSubnets, but takes a
+// context.Context.
+func (c *Controller) GetAllFreeSubnetsWithContext(ctx context.Context, id, mask int) (out []string, err error) {
+	err = c.SendRequestWithContext(ctx, "GET", fmt.Sprintf("/subnets/%d/all_subnets/%d/", id, mask), &struct{}{}, &out)
+	return
+}
+
+// CreateFirstFreeSubnet creates the first free child subnet with the supplied
+// mask in a master subnet by sending a POST request, and returns the new
+// subnet as read back from the API. The subnet is found and created in a
+// single request, so concurrent callers will not be handed the same subnet.
+//
+// Fields set in in, such as the description, are set on the new subnet. The
+// subnet address, mask, section, and master subnet are set by PHPIPAM and
+// should be left blank.
+func (c *Controller) CreateFirstFreeSubnet(id, mask int, in Subnet) (out Subnet, err error) {
+	return c.CreateFirstFreeSubnetWithContext(context.Background(), id, mask, in)
+}
+
+// CreateFirstFreeSubnetWithContext is the same as CreateFirstFreeSubnet, but
+// takes a context.Context.
+func (c *Controller) CreateFirstFreeSubnetWithContext(ctx context.Context, id, mask int, in Subnet) (out Subnet, err error) {
+	var newID int
+	newID, err = c.Client.CreateResourceWithContext(ctx, fmt.Sprintf("/subnets/%d/first_subnet/%d/", id, mask), &in)
+	if err != nil {
+		return
+	}
+	out, err = c.GetSubnetByIDWithContext(ctx, newID)
 	return
 }
 
 // GetAddressesInSubnet GETs the IP addresses for a specific subnet, via a
 // supplied subnet ID.
 func (c *Controller) GetAddressesInSubnet(id int) (out []addresses.Address, err error) {
-	err = c.SendRequest("GET", fmt.Sprintf("/subnets/%d/addresses/", id), &struct{}{}, &out)
+	return c.GetAddressesInSubnetWithContext(context.Background(), id)
+}
+
+// GetAddressesInSubnetWithContext is the same as GetAddressesInSubnet, but
+// takes a context.Context.
+func (c *Controller) GetAddressesInSubnetWithContext(ctx context.Context, id int) (out []addresses.Address, err error) {
+	err = c.SendRequestWithContext(ctx, "GET", fmt.Sprintf("/subnets/%d/addresses/", id), &struct{}{}, &out)
+	return
+}
+
+// PingAddressesInSubnet runs ping checks of the IP addresses in a subnet, via a
+// supplied subnet ID, and returns the results keyed by address ID. Addresses
+// with ExcludePing set are skipped. Up to workers checks are run at once. See
+// addresses.Controller.PingAddresses for details.
+func (c *Controller) PingAddressesInSubnet(id, workers int) (out map[int]addresses.PingResult, err error) {
+	return c.PingAddressesInSubnetWithContext(context.Background(), id, workers)
+}
+
+// PingAddressesInSubnetWithContext is the same as PingAddressesInSubnet, but
+// takes a context.Context.
+func (c *Controller) PingAddressesInSubnetWithContext(ctx context.Context, id, workers int) (out map[int]addresses.PingResult, err error) {
+	var addrs []addresses.Address
+	addrs, err = c.GetAddressesInSubnetWithContext(ctx, id)
+	// Subnets without addresses are returned as not found.
+	if err != nil {
+		if phpipam.IsNotFound(err) {
+			out, err = map[int]addresses.PingResult{}, nil
+		}
+		return
+	}
+	var ids []int
+	for _, a := range addrs {
+		if !a.ExcludePing {
+			ids = append(ids, a.ID)
+		}
+	}
+	out, err = addresses.NewController(c.Session).PingAddressesWithContext(ctx, ids, workers)
 	return
 }
 
 // GetSubnetCustomFieldsSchema GETs the custom fields for the subnets controller via
 // client.GetCustomFieldsSchema.
 func (c *Controller) GetSubnetCustomFieldsSchema() (out map[string]phpipam.CustomField, err error) {
-	out, err = c.Client.GetCustomFieldsSchema("subnets")
+	return c.GetSubnetCustomFieldsSchemaWithContext(context.Background())
+}
+
+// GetSubnetCustomFieldsSchemaWithContext is the same as
+// GetSubnetCustomFieldsSchema, but takes a context.Context.
+func (c *Controller) GetSubnetCustomFieldsSchemaWithContext(ctx context.Context) (out map[string]phpipam.CustomField, err error) {
+	out, err = c.Client.GetCustomFieldsSchemaWithContext(ctx, "subnets")
 	return
 }
 
 // GetSubnetCustomFields GETs the custom fields for a subnet via
 // client.GetCustomFields.
 func (c *Controller) GetSubnetCustomFields(id int) (out map[string]interface{}, err error) {
-	out, err = c.Client.GetCustomFields(id, "subnets")
+	return c.GetSubnetCustomFieldsWithContext(context.Background(), id)
+}
+
+// GetSubnetCustomFieldsWithContext is the same as GetSubnetCustomFields, but
+// takes a context.Context.
+func (c *Controller) GetSubnetCustomFieldsWithContext(ctx context.Context, id int) (out map[string]interface{}, err error) {
+	out, err = c.Client.GetCustomFieldsWithContext(ctx, id, "subnets")
 	return
 }
 
 // UpdateSubnet updates a subnet by sending a PATCH request.
 //
-// Note you cannot use this function to update a subnet's CIDR - to split,
-// grow, or renumber a subnet, you need to use other methods that are currently
-// not implemented in this SDK. See the API spec for more details.
+// Note you cannot use this function to update a subnet's CIDR - use
+// ResizeSubnet to grow or shrink a subnet, or SplitSubnet to split it into
+// smaller subnets.
 func (c *Controller) UpdateSubnet(in Subnet) (message string, err error) {
-	err = c.SendRequest("PATCH", "/subnets/", &in, &message)
+	return c.UpdateSubnetWithContext(context.Background(), in)
+}
+
+// UpdateSubnetWithContext is the same as UpdateSubnet, but takes a
+// context.Context.
+func (c *Controller) UpdateSubnetWithContext(ctx context.Context, in Subnet) (message string, err error) {
+	err = c.SendRequestWithContext(ctx, "PATCH", "/subnets/", &in, &message)
+	return
+}
+
+// ResizeSubnet changes the mask of a subnet by sending a PATCH request. The
+// subnet keeps its address - when growing a subnet, the address must be valid
+// for the new mask.
+//
+// Before the request is sent, the subnet's addresses are checked against the
+// new range, and an error is returned if shrinking the subnet would leave any
+// of them outside of it.
+func (c *Controller) ResizeSubnet(id, mask int) (message string, err error) {
+	return c.ResizeSubnetWithContext(context.Background(), id, mask)
+}
+
+// ResizeSubnetWithContext is the same as ResizeSubnet, but takes a
+// context.Context.
+func (c *Controller) ResizeSubnetWithContext(ctx context.Context, id, mask int) (message string, err error) {
+	var sn Subnet
+	sn, err = c.GetSubnetByIDWithContext(ctx, id)
+	if err != nil {
+		return
+	}
+	var addrs []addresses.Address
+	addrs, err = c.GetAddressesInSubnetWithContext(ctx, id)
+	// Subnets without addresses are returned as not found.
+	if err != nil && !phpipam.IsNotFound(err) {
+		return
+	}
+	var orphans []string
+	orphans, err = orphanedAddresses(sn, mask, addrs)
+	if err != nil {
+		return
+	}
+	if len(orphans) > 0 {
+		err = fmt.Errorf("Resizing subnet %s/%d to /%d would leave %d address(es) outside of it: %s", sn.SubnetAddress, sn.Mask, mask, len(orphans), strings.Join(orphans, ", "))
+		return
+	}
+
+	in := struct {
+		Mask int `json:"mask,string"`
+	}{
+		Mask: mask,
+	}
+	err = c.SendRequestWithContext(ctx, "PATCH", fmt.Sprintf("/subnets/%d/resize/", id), &in, &message)
+	return
+}
+
+// orphanedAddresses returns the addresses in addrs that would fall outside of
+// subnet s if its mask was changed to mask.
+func orphanedAddresses(s Subnet, mask int, addrs []addresses.Address) ([]string, error) {
+	ip := net.ParseIP(s.SubnetAddress)
+	if ip == nil {
+		return nil, fmt.Errorf("Invalid subnet address %q", s.SubnetAddress)
+	}
+	bits := 128
+	if ip4 := ip.To4(); ip4 != nil {
+		ip, bits = ip4, 32
+	}
+	if mask < 0 || mask > bits {
+		return nil, fmt.Errorf("Invalid mask /%d for subnet %s", mask, s.SubnetAddress)
+	}
+	n := &net.IPNet{IP: ip.Mask(net.CIDRMask(mask, bits)), Mask: net.CIDRMask(mask, bits)}
+
+	var out []string
+	for _, a := range addrs {
+		if aip := net.ParseIP(a.IPAddress); aip == nil || !n.Contains(aip) {
+			out = append(out, a.IPAddress)
+		}
+	}
+	return out, nil
+}
+
+// SplitSubnet splits a subnet into number smaller subnets of equal size by
+// sending a PATCH request. number needs to be a power of two. The addresses in
+// the subnet are moved to the new subnets.
+func (c *Controller) SplitSubnet(id, number int) (message string, err error) {
+	return c.SplitSubnetWithContext(context.Background(), id, number)
+}
+
+// SplitSubnetWithContext is the same as SplitSubnet, but takes a
+// context.Context.
+func (c *Controller) SplitSubnetWithContext(ctx context.Context, id, number int) (message string, err error) {
+	if number < 2 || number&(number-1) != 0 {
+		err = fmt.Errorf("Cannot split subnet %d into %d subnets - number needs to be a power of two", id, number)
+		return
+	}
+	in := struct {
+		Number int `json:"number,string"`
+	}{
+		Number: number,
+	}
+	err = c.SendRequestWithContext(ctx, "PATCH", fmt.Sprintf("/subnets/%d/split/", id), &in, &message)
+	return
+}
+
+// TruncateSubnet deletes all of the addresses in a subnet, leaving the subnet
+// itself in place.
+func (c *Controller) TruncateSubnet(id int) (message string, err error) {
+	return c.TruncateSubnetWithContext(context.Background(), id)
+}
+
+// TruncateSubnetWithContext is the same as TruncateSubnet, but takes a
+// context.Context.
+func (c *Controller) TruncateSubnetWithContext(ctx context.Context, id int) (message string, err error) {
+	err = c.SendRequestWithContext(ctx, "DELETE", fmt.Sprintf("/subnets/%d/truncate/", id), &struct{}{}, &message)
 	return
 }
 
 // UpdateSubnetCustomFields PATCHes the subnet's custom fields via
 // client.UpdateCustomFields.
 func (c *Controller) UpdateSubnetCustomFields(id int, in map[string]interface{}) (message string, err error) {
-	message, err = c.Client.UpdateCustomFields(id, in, "subnets")
+	return c.UpdateSubnetCustomFieldsWithContext(context.Background(), id, in)
+}
+
+// UpdateSubnetCustomFieldsWithContext is the same as UpdateSubnetCustomFields,
+// but takes a context.Context.
+func (c *Controller) UpdateSubnetCustomFieldsWithContext(ctx context.Context, id int, in map[string]interface{}) (message string, err error) {
+	message, err = c.Client.UpdateCustomFieldsWithContext(ctx, id, in, "subnets")
 	return
 }
 
 // DeleteSubnet deletes a subnet by its ID.
 func (c *Controller) DeleteSubnet(id int) (message string, err error) {
-	err = c.SendRequest("DELETE", fmt.Sprintf("/subnets/%d/", id), &struct{}{}, &message)
+	return c.DeleteSubnetWithContext(context.Background(), id)
+}
+
+// DeleteSubnetWithContext is the same as DeleteSubnet, but takes a
+// context.Context.
+func (c *Controller) DeleteSubnetWithContext(ctx context.Context, id int) (message string, err error) {
+	err = c.SendRequestWithContext(ctx, "DELETE", fmt.Sprintf("/subnets/%d/", id), &struct{}{}, &message)
 	return
 }
@@ -1,13 +1,17 @@
 package subnets
 
 import (
+	"encoding/json"
 	"fmt"
 	"log"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"reflect"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/paybyphone/phpipam-sdk-go/controllers/addresses"
 	"github.com/paybyphone/phpipam-sdk-go/phpipam"
@@ -198,6 +202,113 @@ const testGetFirstFreeAddressOutputJSON = `
 }
 `
 
+const testGetFirstFreeSubnetOutputExpected = "10.10.1.0/28"
+const testGetFirstFreeSubnetOutputJSON = `
+{
+  "code": 200,
+  "success": true,
+  "data": "10.10.1.0/28"
+}
+`
+
+var testGetAllFreeSubnetsOutputExpected = []string{
+	"10.10.1.0/26",
+	"10.10.1.64/26",
+	"10.10.1.192/26",
+}
+
+const testGetAllFreeSubnetsOutputJSON = `
+{
+  "code": 200,
+  "success": true,
+  "data": [
+    "10.10.1.0/26",
+    "10.10.1.64/26",
+    "10.10.1.192/26"
+  ]
+}
+`
+
+var testCreateFirstFreeSubnetInput = Subnet{
+	Description: "carved",
+}
+
+const testCreateFirstFreeSubnetOutputJSON = `
+{
+  "code": 201,
+  "success": true,
+  "message": "Subnet created",
+  "id": "12",
+  "data": "10.10.1.0/28",
+  "time": 0.011
+}
+`
+
+var testCreateFirstFreeSubnetOutputExpected = Subnet{
+	ID:             12,
+	SubnetAddress:  "10.10.1.0",
+	Mask:           28,
+	Description:    "carved",
+	SectionID:      1,
+	MasterSubnetID: 8,
+}
+
+const testCreateFirstFreeSubnetGetOutputJSON = `
+{
+  "code": 200,
+  "success": true,
+  "data": {
+    "id": "12",
+    "subnet": "10.10.1.0",
+    "mask": "28",
+    "description": "carved",
+    "sectionId": "1",
+    "masterSubnetId": "8"
+  }
+}
+`
+
+const testResizeSubnetGetOutputJSON = `
+{
+  "code": 200,
+  "success": true,
+  "data": {
+    "id": "8",
+    "subnet": "10.10.1.0",
+    "mask": "24",
+    "sectionId": "1"
+  }
+}
+`
+
+const testResizeSubnetAddressesOutputJSON = `
+{
+  "code": 200,
+  "success": true,
+  "data": [
+    {
+      "id": "1",
+      "subnetId": "8",
+      "ip": "10.10.1.5"
+    },
+    {
+      "id": "2",
+      "subnetId": "8",
+      "ip": "10.10.1.200"
+    }
+  ]
+}
+`
+
+const testResizeSubnetOutputExpected = `Subnet resized`
+const testResizeSubnetOutputJSON = `
+{
+  "code": 200,
+  "success": true,
+  "data": "Subnet resized"
+}
+`
+
 var testGetAddressesInSubnetExpected = []addresses.Address{
 	addresses.Address{
 		ID:          1,
@@ -389,6 +500,97 @@ const testGetAddressesInSubnetJSON = `
 }
 `
 
+var testGetSlavesOutputExpected = []Subnet{
+	Subnet{
+		ID:             9,
+		SubnetAddress:  "10.10.1.0",
+		Mask:           25,
+		SectionID:      1,
+		MasterSubnetID: 8,
+	},
+	Subnet{
+		ID:             10,
+		SubnetAddress:  "10.10.1.128",
+		Mask:           25,
+		SectionID:      1,
+		MasterSubnetID: 8,
+	},
+}
+
+const testGetSlavesOutputJSON = `
+{
+  "code": 200,
+  "success": true,
+  "data": [
+    {
+      "id": "9",
+      "subnet": "10.10.1.0",
+      "mask": "25",
+      "sectionId": "1",
+      "masterSubnetId": "8"
+    },
+    {
+      "id": "10",
+      "subnet": "10.10.1.128",
+      "mask": "25",
+      "sectionId": "1",
+      "masterSubnetId": "8"
+    }
+  ]
+}
+`
+
+const testGetSlavesRecursiveOutputJSON = `
+{
+  "code": 200,
+  "success": true,
+  "data": [
+    {
+      "id": "8",
+      "subnet": "10.10.1.0",
+      "mask": "24",
+      "sectionId": "1",
+      "masterSubnetId": "0"
+    },
+    {
+      "id": "9",
+      "subnet": "10.10.1.0",
+      "mask": "25",
+      "sectionId": "1",
+      "masterSubnetId": "8"
+    },
+    {
+      "id": "11",
+      "subnet": "10.10.1.0",
+      "mask": "26",
+      "sectionId": "1",
+      "masterSubnetId": "9"
+    },
+    {
+      "id": "10",
+      "subnet": "10.10.1.128",
+      "mask": "25",
+      "sectionId": "1",
+      "masterSubnetId": "8"
+    }
+  ]
+}
+`
+
+const testGetSubnetTreeRootOutputJSON = `
+{
+  "code": 200,
+  "success": true,
+  "data": {
+    "id": "8",
+    "subnet": "10.10.1.0",
+    "mask": "24",
+    "sectionId": "1",
+    "masterSubnetId": "0"
+  }
+}
+`
+
 var testGetSubnetCustomFieldsSchemaExpected = map[string]phpipam.CustomField{
 	"CustomTestSubnets": phpipam.CustomField{
 		Name:    "CustomTestSubnets",
@@ -452,6 +654,35 @@ const testDeleteSubnetOutputJSON = `
 }
 `
 
+const testLoginOutputJSON = `
+{
+  "code": 200,
+  "success": true,
+  "data": {
+    "token": "newtoken",
+    "expires": "2999-12-31 23:59:59"
+  }
+}
+`
+
+const testTokenExpiredOutputJSON = `
+{
+  "code": 403,
+  "success": false,
+  "message": "Token expired"
+}
+`
+
+const testCreateSubnetAndGetIDOutputJSON = `
+{
+  "code": 201,
+  "success": true,
+  "message": "Subnet created",
+  "id": "8",
+  "time": 0.012
+}
+`
+
 func newHTTPTestServer(f func(w http.ResponseWriter, r *http.Request)) *httptest.Server {
 	ts := httptest.NewServer(http.HandlerFunc(f))
 	return ts
@@ -471,6 +702,32 @@ func httpCreatedTestServer(output string) *httptest.Server {
 	})
 }
 
+// httpResizeTestServer returns a test server for ResizeSubnet, serving subnet
+// 8 and its addresses. The mask sent in any resize request is stored in mask.
+func httpResizeTestServer(mask *string) *httptest.Server {
+	return newHTTPTestServer(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Content-Type", "application/json")
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/0123456789abcdefgh/subnets/8/":
+			http.Error(w, testResizeSubnetGetOutputJSON, http.StatusOK)
+		case r.Method == "GET" && r.URL.Path == "/0123456789abcdefgh/subnets/8/addresses/":
+			http.Error(w, testResizeSubnetAddressesOutputJSON, http.StatusOK)
+		case r.Method == "PATCH" && r.URL.Path == "/0123456789abcdefgh/subnets/8/resize/":
+			var in struct {
+				Mask string `json:"mask"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			*mask = in.Mask
+			http.Error(w, testResizeSubnetOutputJSON, http.StatusOK)
+		default:
+			http.Error(w, "not found", http.StatusNotFound)
+		}
+	})
+}
+
 func fullSessionConfig() *session.Session {
 	return &session.Session{
 		Config: phpipam.Config{
@@ -484,6 +741,25 @@ func fullSessionConfig() *session.Session {
 	}
 }
 
+// httpTokenExpiredTestServer returns a server that rejects any request that
+// does not carry the token handed out by a login with a token expired error,
+// and serves output otherwise. The number of logins is recorded in logins.
+func httpTokenExpiredTestServer(output string, logins *int32) *httptest.Server {
+	return newHTTPTestServer(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Content-Type", "application/json")
+		switch {
+		case r.Method == "POST" && r.URL.Path == "/0123456789abcdefgh/user/":
+			atomic.AddInt32(logins, 1)
+			time.Sleep(10 * time.Millisecond)
+			http.Error(w, testLoginOutputJSON, http.StatusOK)
+		case r.Header.Get("phpipam-token") != "newtoken":
+			http.Error(w, testTokenExpiredOutputJSON, http.StatusForbidden)
+		default:
+			http.Error(w, output, http.StatusOK)
+		}
+	})
+}
+
 func TestCreateSubnet(t *testing.T) {
 	ts := httpCreatedTestServer(testCreateSubnetOutputJSON)
 	defer ts.Close()
@@ -503,6 +779,67 @@ func TestCreateSubnet(t *testing.T) {
 	}
 }
 
+func TestCreateSubnetAndGetID(t *testing.T) {
+	ts := httpCreatedTestServer(testCreateSubnetAndGetIDOutputJSON)
+	defer ts.Close()
+	sess := fullSessionConfig()
+	sess.Config.Endpoint = ts.URL
+	client := NewController(sess)
+
+	in := testCreateSubnetInput
+	expected := 8
+	actual, err := client.CreateSubnetAndGetID(in)
+	if err != nil {
+		t.Fatalf("Bad: %s", err)
+	}
+
+	if expected != actual {
+		t.Fatalf("Expected %#v, got %#v", expected, actual)
+	}
+}
+
+func TestCreateSubnetAndGet(t *testing.T) {
+	ts := newHTTPTestServer(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Content-Type", "application/json")
+		switch {
+		case r.Method == "POST" && r.URL.Path == "/0123456789abcdefgh/subnets/":
+			http.Error(w, testCreateSubnetAndGetIDOutputJSON, http.StatusCreated)
+		case r.Method == "GET" && r.URL.Path == "/0123456789abcdefgh/subnets/8/":
+			http.Error(w, testGetSubnetByIDOutputJSON, http.StatusOK)
+		default:
+			http.Error(w, "not found", http.StatusNotFound)
+		}
+	})
+	defer ts.Close()
+	sess := fullSessionConfig()
+	sess.Config.Endpoint = ts.URL
+	client := NewController(sess)
+
+	in := testCreateSubnetInput
+	expected := testGetSubnetByIDOutputExpected
+	actual, err := client.CreateSubnetAndGet(in)
+	if err != nil {
+		t.Fatalf("Bad: %s", err)
+	}
+
+	if !reflect.DeepEqual(expected, actual) {
+		t.Fatalf("Expected %#v, got %#v", expected, actual)
+	}
+}
+
+func TestCreateSubnetAndGetIDMissingID(t *testing.T) {
+	ts := httpCreatedTestServer(testCreateSubnetOutputJSON)
+	defer ts.Close()
+	sess := fullSessionConfig()
+	sess.Config.Endpoint = ts.URL
+	client := NewController(sess)
+
+	in := testCreateSubnetInput
+	if _, err := client.CreateSubnetAndGetID(in); err == nil {
+		t.Fatalf("Expected error, got none")
+	}
+}
+
 func TestGetSubnetByID(t *testing.T) {
 	ts := httpOKTestServer(testGetSubnetByIDOutputJSON)
 	defer ts.Close()
@@ -557,6 +894,71 @@ func TestGetFirstFreeAddress(t *testing.T) {
 	}
 }
 
+func TestGetFirstFreeSubnet(t *testing.T) {
+	ts := httpOKTestServer(testGetFirstFreeSubnetOutputJSON)
+	defer ts.Close()
+	sess := fullSessionConfig()
+	sess.Config.Endpoint = ts.URL
+	client := NewController(sess)
+
+	expected := testGetFirstFreeSubnetOutputExpected
+	actual, err := client.GetFirstFreeSubnet(8, 28)
+	if err != nil {
+		t.Fatalf("Bad: %s", err)
+	}
+
+	if expected != actual {
+		t.Fatalf("Expected %s, got %s", expected, actual)
+	}
+}
+
+func TestGetAllFreeSubnets(t *testing.T) {
+	ts := httpOKTestServer(testGetAllFreeSubnetsOutputJSON)
+	defer ts.Close()
+	sess := fullSessionConfig()
+	sess.Config.Endpoint = ts.URL
+	client := NewController(sess)
+
+	expected := testGetAllFreeSubnetsOutputExpected
+	actual, err := client.GetAllFreeSubnets(8, 26)
+	if err != nil {
+		t.Fatalf("Bad: %s", err)
+	}
+
+	if !reflect.DeepEqual(expected, actual) {
+		t.Fatalf("Expected %#v, got %#v", expected, actual)
+	}
+}
+
+func TestCreateFirstFreeSubnet(t *testing.T) {
+	ts := newHTTPTestServer(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Content-Type", "application/json")
+		switch {
+		case r.Method == "POST" && r.URL.Path == "/0123456789abcdefgh/subnets/8/first_subnet/28/":
+			http.Error(w, testCreateFirstFreeSubnetOutputJSON, http.StatusCreated)
+		case r.Method == "GET" && r.URL.Path == "/0123456789abcdefgh/subnets/12/":
+			http.Error(w, testCreateFirstFreeSubnetGetOutputJSON, http.StatusOK)
+		default:
+			http.Error(w, "not found", http.StatusNotFound)
+		}
+	})
+	defer ts.Close()
+	sess := fullSessionConfig()
+	sess.Config.Endpoint = ts.URL
+	client := NewController(sess)
+
+	in := testCreateFirstFreeSubnetInput
+	expected := testCreateFirstFreeSubnetOutputExpected
+	actual, err := client.CreateFirstFreeSubnet(8, 28, in)
+	if err != nil {
+		t.Fatalf("Bad: %s", err)
+	}
+
+	if !reflect.DeepEqual(expected, actual) {
+		t.Fatalf("Expected %#v, got %#v", expected, actual)
+	}
+}
+
 func TestGetAddressesInSubnet(t *testing.T) {
 	ts := httpOKTestServer(testGetAddressesInSubnetJSON)
 	defer ts.Close()
@@ -575,6 +977,148 @@ func TestGetAddressesInSubnet(t *testing.T) {
 	}
 }
 
+const testPingAddressesInSubnetAddressesJSON = `
+{
+  "code": 200,
+  "success": true,
+  "data": [
+    {
+      "id": "1",
+      "subnetId": "3",
+      "ip": "10.10.1.3"
+    },
+    {
+      "id": "2",
+      "subnetId": "3",
+      "ip": "10.10.1.4",
+      "excludePing": "1"
+    },
+    {
+      "id": "3",
+      "subnetId": "3",
+      "ip": "10.10.1.5"
+    }
+  ]
+}
+`
+
+const testPingAddressesInSubnetPingJSON = `
+{
+  "code": 200,
+  "success": true,
+  "data": {
+    "scan_type": "ping",
+    "exit_code": 0,
+    "result_code": "OK",
+    "message": "Address online"
+  }
+}
+`
+
+func TestPingAddressesInSubnet(t *testing.T) {
+	var mu sync.Mutex
+	var pinged []string
+	ts := newHTTPTestServer(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/0123456789abcdefgh/subnets/3/addresses/":
+			http.Error(w, testPingAddressesInSubnetAddressesJSON, http.StatusOK)
+		case "/0123456789abcdefgh/addresses/1/ping/", "/0123456789abcdefgh/addresses/3/ping/":
+			mu.Lock()
+			pinged = append(pinged, r.URL.Path)
+			mu.Unlock()
+			http.Error(w, testPingAddressesInSubnetPingJSON, http.StatusOK)
+		default:
+			http.Error(w, "not found", http.StatusNotFound)
+		}
+	})
+	defer ts.Close()
+	sess := fullSessionConfig()
+	sess.Config.Endpoint = ts.URL
+	client := NewController(sess)
+
+	out, err := client.PingAddressesInSubnet(3, 2)
+	if err != nil {
+		t.Fatalf("Bad: %s", err)
+	}
+	if len(out) != 2 || out[1].Status != addresses.PingOnline || out[3].Status != addresses.PingOnline {
+		t.Fatalf("Unexpected results: %#v", out)
+	}
+	if len(pinged) != 2 {
+		t.Fatalf("Expected 2 addresses to be pinged, got %v", pinged)
+	}
+}
+
+func TestPingAddressesInSubnetNoAddresses(t *testing.T) {
+	ts := newHTTPTestServer(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Content-Type", "application/json")
+		http.Error(w, `{"code":404,"success":false,"message":"No addresses found"}`, http.StatusNotFound)
+	})
+	defer ts.Close()
+	sess := fullSessionConfig()
+	sess.Config.Endpoint = ts.URL
+	client := NewController(sess)
+
+	out, err := client.PingAddressesInSubnet(3, 2)
+	if err != nil {
+		t.Fatalf("Bad: %s", err)
+	}
+	if len(out) != 0 {
+		t.Fatalf("Expected no results, got %#v", out)
+	}
+}
+
+func TestGetSlaves(t *testing.T) {
+	ts := httpOKTestServer(testGetSlavesOutputJSON)
+	defer ts.Close()
+	sess := fullSessionConfig()
+	sess.Config.Endpoint = ts.URL
+	client := NewController(sess)
+
+	expected := testGetSlavesOutputExpected
+	actual, err := client.GetSlaves(8)
+	if err != nil {
+		t.Fatalf("Bad: %s", err)
+	}
+
+	if !reflect.DeepEqual(expected, actual) {
+		t.Fatalf("Expected %#v, got %#v", expected, actual)
+	}
+}
+
+func TestGetSubnetTree(t *testing.T) {
+	ts := newHTTPTestServer(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/0123456789abcdefgh/subnets/8/":
+			http.Error(w, testGetSubnetTreeRootOutputJSON, http.StatusOK)
+		case "/0123456789abcdefgh/subnets/8/slaves_recursive/":
+			http.Error(w, testGetSlavesRecursiveOutputJSON, http.StatusOK)
+		default:
+			http.Error(w, "not found", http.StatusNotFound)
+		}
+	})
+	defer ts.Close()
+	sess := fullSessionConfig()
+	sess.Config.Endpoint = ts.URL
+	client := NewController(sess)
+
+	actual, err := client.GetSubnetTree(8)
+	if err != nil {
+		t.Fatalf("Bad: %s", err)
+	}
+
+	var visited []int
+	actual.Walk(func(n *Node, depth int) error {
+		visited = append(visited, n.Subnet.ID)
+		return nil
+	})
+	expected := []int{8, 9, 11, 10}
+	if !reflect.DeepEqual(expected, visited) {
+		t.Fatalf("Expected %v, got %v", expected, visited)
+	}
+}
+
 func TestGetSubnetCustomFieldsSchema(t *testing.T) {
 	ts := httpOKTestServer(testGetSubnetCustomFieldsSchemaJSON)
 	defer ts.Close()
@@ -612,6 +1156,120 @@ func TestUpdateSubnet(t *testing.T) {
 	}
 }
 
+func TestOrphanedAddresses(t *testing.T) {
+	sn := Subnet{SubnetAddress: "10.10.1.0", Mask: 24}
+	addrs := []addresses.Address{
+		addresses.Address{IPAddress: "10.10.1.5"},
+		addresses.Address{IPAddress: "10.10.1.64"},
+		addresses.Address{IPAddress: "10.10.1.200"},
+	}
+	cases := []struct {
+		mask     int
+		expected []string
+	}{
+		{24, nil},
+		{23, nil},
+		{26, []string{"10.10.1.64", "10.10.1.200"}},
+		{25, []string{"10.10.1.200"}},
+	}
+	for _, tc := range cases {
+		actual, err := orphanedAddresses(sn, tc.mask, addrs)
+		if err != nil {
+			t.Fatalf("Bad: %s", err)
+		}
+		if !reflect.DeepEqual(tc.expected, actual) {
+			t.Fatalf("/%d: expected %#v, got %#v", tc.mask, tc.expected, actual)
+		}
+	}
+
+	if _, err := orphanedAddresses(sn, 33, addrs); err == nil {
+		t.Fatalf("Expected error for invalid mask, got none")
+	}
+}
+
+func TestResizeSubnet(t *testing.T) {
+	var mask string
+	ts := httpResizeTestServer(&mask)
+	defer ts.Close()
+	sess := fullSessionConfig()
+	sess.Config.Endpoint = ts.URL
+	client := NewController(sess)
+
+	expected := testResizeSubnetOutputExpected
+	actual, err := client.ResizeSubnet(8, 23)
+	if err != nil {
+		t.Fatalf("Bad: %s", err)
+	}
+	if expected != actual {
+		t.Fatalf("Expected %s, got %s", expected, actual)
+	}
+	if mask != "23" {
+		t.Fatalf("Expected mask 23 to be sent, got %q", mask)
+	}
+}
+
+func TestResizeSubnetOrphansAddresses(t *testing.T) {
+	var mask string
+	ts := httpResizeTestServer(&mask)
+	defer ts.Close()
+	sess := fullSessionConfig()
+	sess.Config.Endpoint = ts.URL
+	client := NewController(sess)
+
+	_, err := client.ResizeSubnet(8, 25)
+	if err == nil {
+		t.Fatalf("Expected error, got none")
+	}
+	expected := "Resizing subnet 10.10.1.0/24 to /25 would leave 1 address(es) outside of it: 10.10.1.200"
+	if expected != err.Error() {
+		t.Fatalf("Expected %q, got %q", expected, err.Error())
+	}
+	if mask != "" {
+		t.Fatalf("Expected no resize request to be sent")
+	}
+}
+
+func TestSplitSubnet(t *testing.T) {
+	ts := httpOKTestServer(testUpdateSubnetOutputJSON)
+	defer ts.Close()
+	sess := fullSessionConfig()
+	sess.Config.Endpoint = ts.URL
+	client := NewController(sess)
+
+	if _, err := client.SplitSubnet(8, 4); err != nil {
+		t.Fatalf("Bad: %s", err)
+	}
+	for _, n := range []int{0, 1, 3, 6} {
+		if _, err := client.SplitSubnet(8, n); err == nil {
+			t.Fatalf("Expected error splitting into %d subnets, got none", n)
+		}
+	}
+}
+
+func TestTruncateSubnet(t *testing.T) {
+	ts := newHTTPTestServer(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Content-Type", "application/json")
+		if r.Method != "DELETE" || r.URL.Path != "/0123456789abcdefgh/subnets/8/truncate/" {
+			http.Error(w, "not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, testDeleteSubnetOutputJSON, http.StatusOK)
+	})
+	defer ts.Close()
+	sess := fullSessionConfig()
+	sess.Config.Endpoint = ts.URL
+	client := NewController(sess)
+
+	expected := testDeleteSubnetOutputExpected
+	actual, err := client.TruncateSubnet(8)
+	if err != nil {
+		t.Fatalf("Bad: %s", err)
+	}
+	if expected != actual {
+		t.Fatalf("Expected %s, got %s", expected, actual)
+	}
+}
+
 func TestDeleteSubnet(t *testing.T) {
 	ts := httpOKTestServer(testDeleteSubnetOutputJSON)
 	defer ts.Close()
@@ -630,6 +1288,42 @@ func TestDeleteSubnet(t *testing.T) {
 	}
 }
 
+// TestConcurrentControllersSharedSession runs requests from several
+// controllers sharing a session with an expired token concurrently, to ensure
+// that the token is only refreshed once. Run with -race to check for data
+// races on the session.
+func TestConcurrentControllersSharedSession(t *testing.T) {
+	var logins int32
+	ts := httpTokenExpiredTestServer(testGetAddressesInSubnetJSON, &logins)
+	defer ts.Close()
+	sess := fullSessionConfig()
+	sess.Config.Endpoint = ts.URL
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			c := NewController(sess)
+			if _, err := c.GetAddressesInSubnet(3); err != nil {
+				t.Errorf("Bad: %s", err)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			c := addresses.NewController(sess)
+			if _, err := c.GetAddressesByIP("10.10.1.3"); err != nil {
+				t.Errorf("Bad: %s", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if n := atomic.LoadInt32(&logins); n != 1 {
+		t.Fatalf("Expected 1 login, got %d", n)
+	}
+}
+
 // testAccSubnetCRUDCreate tests the creation part of the subnets controller
 // CRUD acceptance test.
 func testAccSubnetCRUDCreate(t *testing.T, sess *session.Session, s Subnet) {
@@ -749,7 +1443,7 @@ func TestAccSubnetCRUD(t *testing.T) {
 	sess := session.NewSession()
 	subnet := testCreateSubnetInput
 	// Permissions get added even though they are optional
-	subnet.Permissions = "{\"3\":\"1\",\"2\":\"2\"}"
+	subnet.Permissions = phpipam.Permissions{3: phpipam.AccessRead, 2: phpipam.AccessWrite}
 	if os.Getenv("TESTACC_CUSTOM_NESTED") != "" {
 		subnet.CustomFields = map[string]interface{}{
 			"CustomTestSubnets":  "foobar",
@@ -858,7 +1552,7 @@ func TestAccSubnetCustomFieldUpdateRead(t *testing.T) {
 	// testing that works off of existing data.
 	subnet := testCreateSubnetInput
 	// Permissions get added even though they are optional
-	subnet.Permissions = "{\"3\":\"1\",\"2\":\"2\"}"
+	subnet.Permissions = phpipam.Permissions{3: phpipam.AccessRead, 2: phpipam.AccessWrite}
 	testAccSubnetCRUDCreate(t, sess, subnet)
 	subnet.ID = testAccSubnetCRUDReadByCIDR(t, sess, subnet)
 
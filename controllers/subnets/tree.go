@@ -0,0 +1,81 @@
+package subnets
+
+import (
+	"errors"
+)
+
+// Node is a subnet in a subnet tree, along with its child subnets.
+type Node struct {
+	// The subnet.
+	Subnet Subnet
+
+	// The child subnets of the subnet, in the order they were supplied to
+	// BuildTree.
+	Children []*Node
+}
+
+// SkipChildren can be returned from a WalkFunc to skip the children of the
+// subnet currently being visited. The walk continues with the subnet's
+// siblings.
+var SkipChildren = errors.New("skip children")
+
+// WalkFunc is the type of the function called for each subnet visited by
+// Walk. depth is 0 for the subnet that the walk started at, 1 for its
+// children, and so on.
+//
+// If the function returns SkipChildren, the children of the subnet are not
+// visited. Any other non-nil error stops the walk, and is returned by Walk.
+type WalkFunc func(n *Node, depth int) error
+
+// BuildTree arranges a flat list of subnets into trees by their
+// MasterSubnetID, and returns the root nodes. A subnet is a root if its master
+// subnet is not in the list, such as a top-level subnet of a section.
+func BuildTree(in []Subnet) []*Node {
+	nodes := make(map[int]*Node, len(in))
+	for _, s := range in {
+		nodes[s.ID] = &Node{Subnet: s}
+	}
+
+	var roots []*Node
+	for _, s := range in {
+		n := nodes[s.ID]
+		if p, ok := nodes[s.MasterSubnetID]; ok && s.MasterSubnetID != s.ID {
+			p.Children = append(p.Children, n)
+		} else {
+			roots = append(roots, n)
+		}
+	}
+	return roots
+}
+
+// Walk visits n and all of its descendants depth-first, calling fn for each
+// subnet. Parents are visited before their children.
+func (n *Node) Walk(fn WalkFunc) error {
+	return n.walk(fn, 0)
+}
+
+func (n *Node) walk(fn WalkFunc, depth int) error {
+	switch err := fn(n, depth); err {
+	case nil:
+	case SkipChildren:
+		return nil
+	default:
+		return err
+	}
+	for _, c := range n.Children {
+		if err := c.walk(fn, depth+1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WalkTree walks each of the supplied root nodes in turn. See Node.Walk.
+func WalkTree(roots []*Node, fn WalkFunc) error {
+	for _, n := range roots {
+		if err := n.Walk(fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package subnets
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+var testBuildTreeInput = []Subnet{
+	Subnet{ID: 1, SubnetAddress: "10.0.0.0", Mask: 8},
+	Subnet{ID: 2, SubnetAddress: "10.1.0.0", Mask: 16, MasterSubnetID: 1},
+	Subnet{ID: 3, SubnetAddress: "10.1.1.0", Mask: 24, MasterSubnetID: 2},
+	Subnet{ID: 4, SubnetAddress: "10.2.0.0", Mask: 16, MasterSubnetID: 1},
+	Subnet{ID: 5, SubnetAddress: "192.168.0.0", Mask: 16},
+	Subnet{ID: 6, SubnetAddress: "172.16.1.0", Mask: 24, MasterSubnetID: 99},
+}
+
+// testWalkOrder renders the subnets visited by a walk as "address@depth" strings.
+func testWalkOrder(t *testing.T, roots []*Node, skip int) []string {
+	var out []string
+	err := WalkTree(roots, func(n *Node, depth int) error {
+		out = append(out, fmt.Sprintf("%s@%d", n.Subnet.SubnetAddress, depth))
+		if n.Subnet.ID == skip {
+			return SkipChildren
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Bad: %s", err)
+	}
+	return out
+}
+
+func TestBuildTree(t *testing.T) {
+	roots := BuildTree(testBuildTreeInput)
+
+	var ids []int
+	for _, n := range roots {
+		ids = append(ids, n.Subnet.ID)
+	}
+	expected := []int{1, 5, 6}
+	if !reflect.DeepEqual(expected, ids) {
+		t.Fatalf("Expected roots %v, got %v", expected, ids)
+	}
+	if len(roots[0].Children) != 2 || roots[0].Children[0].Children[0].Subnet.ID != 3 {
+		t.Fatalf("Unexpected tree under 10.0.0.0/8: %#v", roots[0])
+	}
+}
+
+func TestWalkTree(t *testing.T) {
+	expected := []string{
+		"10.0.0.0@0",
+		"10.1.0.0@1",
+		"10.1.1.0@2",
+		"10.2.0.0@1",
+		"192.168.0.0@0",
+		"172.16.1.0@0",
+	}
+	actual := testWalkOrder(t, BuildTree(testBuildTreeInput), 0)
+	if !reflect.DeepEqual(expected, actual) {
+		t.Fatalf("Expected %v, got %v", expected, actual)
+	}
+}
+
+func TestWalkTreeSkipChildren(t *testing.T) {
+	expected := []string{
+		"10.0.0.0@0",
+		"10.1.0.0@1",
+		"10.2.0.0@1",
+		"192.168.0.0@0",
+		"172.16.1.0@0",
+	}
+	actual := testWalkOrder(t, BuildTree(testBuildTreeInput), 2)
+	if !reflect.DeepEqual(expected, actual) {
+		t.Fatalf("Expected %v, got %v", expected, actual)
+	}
+}
+
+func TestWalkTreeError(t *testing.T) {
+	stop := errors.New("stop")
+	var visited int
+	err := WalkTree(BuildTree(testBuildTreeInput), func(n *Node, depth int) error {
+		visited++
+		if n.Subnet.ID == 3 {
+			return stop
+		}
+		return nil
+	})
+	if err != stop {
+		t.Fatalf("Expected %s, got %v", stop, err)
+	}
+	if visited != 3 {
+		t.Fatalf("Expected walk to stop after 3 subnets, visited %d", visited)
+	}
+}
@@ -0,0 +1,167 @@
+package subnets
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Usage represents the address usage of a subnet.
+//
+// Host counts are float64 values, as IPv6 subnets can hold more hosts than fit
+// in an int. Counts are exact up to 2^53 hosts.
+type Usage struct {
+	// The number of addresses in use.
+	Used float64
+
+	// The number of usable host addresses in the subnet.
+	MaxHosts float64
+
+	// The number of free host addresses in the subnet.
+	FreeHosts float64
+
+	// The percentage of host addresses that are free.
+	FreePercent float64
+
+	// The percentage of host addresses in each address state, keyed by the
+	// name of the state's tag (i.e. "Used", "Reserved", "Offline", "DHCP").
+	StatePercent map[string]float64
+
+	// The number of host addresses in each address state, keyed the same way
+	// as StatePercent. PHPIPAM only reports percentages, so these are worked
+	// out from StatePercent and MaxHosts, rounded to the nearest host.
+	StateCount map[string]float64
+}
+
+// UnmarshalJSON implements json.Unmarshaler for Usage. Counts can be returned
+// as either strings or numbers, and state percentages are returned as
+// "<state>_percent" keys, so the response is parsed by hand. StateCount is
+// derived from the parsed percentages.
+func (u *Usage) UnmarshalJSON(b []byte) error {
+	var m map[string]json.RawMessage
+	if err := json.Unmarshal(b, &m); err != nil {
+		return err
+	}
+	*u = Usage{}
+	for k, v := range m {
+		f, ok := parseUsageNumber(v)
+		if !ok {
+			continue
+		}
+		switch {
+		case k == "used":
+			u.Used = f
+		case k == "maxhosts":
+			u.MaxHosts = f
+		case k == "freehosts":
+			u.FreeHosts = f
+		case k == "freehosts_percent":
+			u.FreePercent = f
+		case strings.HasSuffix(k, "_percent"):
+			if u.StatePercent == nil {
+				u.StatePercent = make(map[string]float64)
+			}
+			u.StatePercent[strings.TrimSuffix(k, "_percent")] = f
+		}
+	}
+	for k, p := range u.StatePercent {
+		if u.StateCount == nil {
+			u.StateCount = make(map[string]float64)
+		}
+		u.StateCount[k] = math.Round(p * u.MaxHosts / 100)
+	}
+	return nil
+}
+
+// parseUsageNumber parses a usage value that is either a JSON number or a
+// string holding a number. false is returned for anything else.
+func parseUsageNumber(b json.RawMessage) (float64, bool) {
+	var f float64
+	if err := json.Unmarshal(b, &f); err == nil {
+		return f, true
+	}
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return 0, false
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, false
+	}
+	return f, true
+}
+
+// Add returns the combined usage of u and v. Counts are summed, and
+// percentages are recalculated from the combined host counts.
+func (u Usage) Add(v Usage) Usage {
+	out := Usage{
+		Used:      u.Used + v.Used,
+		MaxHosts:  u.MaxHosts + v.MaxHosts,
+		FreeHosts: u.FreeHosts + v.FreeHosts,
+	}
+	for _, x := range []Usage{u, v} {
+		for k, n := range x.StateCount {
+			if out.StateCount == nil {
+				out.StateCount = make(map[string]float64)
+			}
+			out.StateCount[k] += n
+		}
+	}
+	if out.MaxHosts == 0 {
+		return out
+	}
+	out.FreePercent = out.FreeHosts / out.MaxHosts * 100
+	for _, x := range []Usage{u, v} {
+		for k, p := range x.StatePercent {
+			if out.StatePercent == nil {
+				out.StatePercent = make(map[string]float64)
+			}
+			out.StatePercent[k] += p * x.MaxHosts / out.MaxHosts
+		}
+	}
+	return out
+}
+
+// GetSubnetUsage GETs the address usage of a subnet, via a supplied subnet ID.
+// The usage of a master subnet includes the usage of its slaves.
+func (c *Controller) GetSubnetUsage(id int) (out Usage, err error) {
+	return c.GetSubnetUsageWithContext(context.Background(), id)
+}
+
+// GetSubnetUsageWithContext is the same as GetSubnetUsage, but takes a
+// context.Context.
+func (c *Controller) GetSubnetUsageWithContext(ctx context.Context, id int) (out Usage, err error) {
+	err = c.SendRequestWithContext(ctx, "GET", fmt.Sprintf("/subnets/%d/usage/", id), &struct{}{}, &out)
+	return
+}
+
+// GetTreeUsage rolls up the address usage of the subnet trees rooted at
+// roots, such as the trees returned by BuildTree for the subnets of a section.
+//
+// As PHPIPAM includes the usage of slave subnets in the usage of their master,
+// only the usage of each root subnet is fetched. Folders have no usage of
+// their own, so the subnets in a folder are rolled up in its place.
+func (c *Controller) GetTreeUsage(roots []*Node) (out Usage, err error) {
+	return c.GetTreeUsageWithContext(context.Background(), roots)
+}
+
+// GetTreeUsageWithContext is the same as GetTreeUsage, but takes a
+// context.Context.
+func (c *Controller) GetTreeUsageWithContext(ctx context.Context, roots []*Node) (out Usage, err error) {
+	for _, n := range roots {
+		var u Usage
+		if n.Subnet.IsFolder {
+			u, err = c.GetTreeUsageWithContext(ctx, n.Children)
+		} else {
+			u, err = c.GetSubnetUsageWithContext(ctx, n.Subnet.ID)
+		}
+		if err != nil {
+			return
+		}
+		out = out.Add(u)
+	}
+	return
+}
@@ -0,0 +1,170 @@
+package subnets
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+const testGetSubnetUsageOutputJSON = `
+{
+  "code": 200,
+  "success": true,
+  "data": {
+    "used": "3",
+    "maxhosts": "254",
+    "freehosts": "251",
+    "freehosts_percent": 98.82,
+    "Offline_percent": 0,
+    "Used_percent": 0.79,
+    "Reserved_percent": 0.39,
+    "DHCP_percent": 0
+  }
+}
+`
+
+var testGetSubnetUsageOutputExpected = Usage{
+	Used:        3,
+	MaxHosts:    254,
+	FreeHosts:   251,
+	FreePercent: 98.82,
+	StatePercent: map[string]float64{
+		"Offline":  0,
+		"Used":     0.79,
+		"Reserved": 0.39,
+		"DHCP":     0,
+	},
+	StateCount: map[string]float64{
+		"Offline":  0,
+		"Used":     2,
+		"Reserved": 1,
+		"DHCP":     0,
+	},
+}
+
+const testIPv6UsageJSON = `
+{
+  "used": 1,
+  "maxhosts": "18446744073709551616",
+  "freehosts": "18446744073709551615",
+  "freehosts_percent": "100"
+}
+`
+
+func TestUsageUnmarshalJSONIPv6(t *testing.T) {
+	var actual Usage
+	if err := json.Unmarshal([]byte(testIPv6UsageJSON), &actual); err != nil {
+		t.Fatalf("Bad: %s", err)
+	}
+	expected := Usage{
+		Used:        1,
+		MaxHosts:    18446744073709551616,
+		FreeHosts:   18446744073709551615,
+		FreePercent: 100,
+	}
+	if !reflect.DeepEqual(expected, actual) {
+		t.Fatalf("Expected %#v, got %#v", expected, actual)
+	}
+}
+
+func TestUsageAdd(t *testing.T) {
+	a := Usage{
+		Used:         2,
+		MaxHosts:     254,
+		FreeHosts:    252,
+		StatePercent: map[string]float64{"Used": 100 * 2.0 / 254},
+		StateCount:   map[string]float64{"Used": 2},
+	}
+	b := Usage{
+		Used:         14,
+		MaxHosts:     14,
+		FreeHosts:    0,
+		StatePercent: map[string]float64{"Used": 50, "Reserved": 50},
+		StateCount:   map[string]float64{"Used": 7, "Reserved": 7},
+	}
+	actual := a.Add(b)
+
+	if actual.Used != 16 || actual.MaxHosts != 268 || actual.FreeHosts != 252 {
+		t.Fatalf("Unexpected counts: %#v", actual)
+	}
+	if p := 100 * 252.0 / 268; actual.FreePercent-p > 1e-9 || actual.FreePercent-p < -1e-9 {
+		t.Fatalf("Expected free percentage %f, got %f", p, actual.FreePercent)
+	}
+	// 2 + 7 used hosts, 7 reserved hosts, out of 268.
+	if expected := map[string]float64{"Used": 9, "Reserved": 7}; !reflect.DeepEqual(expected, actual.StateCount) {
+		t.Fatalf("Expected state counts %v, got %v", expected, actual.StateCount)
+	}
+	for k, n := range map[string]float64{"Used": 9, "Reserved": 7} {
+		expected := 100 * n / 268
+		if d := actual.StatePercent[k] - expected; d > 1e-9 || d < -1e-9 {
+			t.Fatalf("Expected %s percentage %f, got %f", k, expected, actual.StatePercent[k])
+		}
+	}
+
+	if zero := (Usage{}).Add(Usage{}); !reflect.DeepEqual(Usage{}, zero) {
+		t.Fatalf("Expected zero usage, got %#v", zero)
+	}
+}
+
+func TestGetSubnetUsage(t *testing.T) {
+	ts := httpOKTestServer(testGetSubnetUsageOutputJSON)
+	defer ts.Close()
+	sess := fullSessionConfig()
+	sess.Config.Endpoint = ts.URL
+	client := NewController(sess)
+
+	expected := testGetSubnetUsageOutputExpected
+	actual, err := client.GetSubnetUsage(3)
+	if err != nil {
+		t.Fatalf("Bad: %s", err)
+	}
+
+	if !reflect.DeepEqual(expected, actual) {
+		t.Fatalf("Expected %#v, got %#v", expected, actual)
+	}
+}
+
+func TestGetTreeUsage(t *testing.T) {
+	var requested []string
+	ts := newHTTPTestServer(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Content-Type", "application/json")
+		requested = append(requested, r.URL.Path)
+		http.Error(w, testGetSubnetUsageOutputJSON, http.StatusOK)
+	})
+	defer ts.Close()
+	sess := fullSessionConfig()
+	sess.Config.Endpoint = ts.URL
+	client := NewController(sess)
+
+	roots := BuildTree([]Subnet{
+		Subnet{ID: 1, SubnetAddress: "10.0.0.0", Mask: 16},
+		Subnet{ID: 2, SubnetAddress: "10.0.1.0", Mask: 24, MasterSubnetID: 1},
+		Subnet{ID: 3, IsFolder: true},
+		Subnet{ID: 4, SubnetAddress: "10.1.0.0", Mask: 24, MasterSubnetID: 3},
+	})
+	actual, err := client.GetTreeUsage(roots)
+	if err != nil {
+		t.Fatalf("Bad: %s", err)
+	}
+
+	expectedRequests := []string{
+		"/0123456789abcdefgh/subnets/1/usage/",
+		"/0123456789abcdefgh/subnets/4/usage/",
+	}
+	if !reflect.DeepEqual(expectedRequests, requested) {
+		t.Fatalf("Expected requests %v, got %v", expectedRequests, requested)
+	}
+	if actual.Used != 6 || actual.MaxHosts != 508 || actual.FreeHosts != 502 {
+		t.Fatalf("Unexpected usage: %#v", actual)
+	}
+	expectedCounts := map[string]float64{
+		"Offline":  0,
+		"Used":     4,
+		"Reserved": 2,
+		"DHCP":     0,
+	}
+	if !reflect.DeepEqual(expectedCounts, actual.StateCount) {
+		t.Fatalf("Expected state counts %v, got %v", expectedCounts, actual.StateCount)
+	}
+}
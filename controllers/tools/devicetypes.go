@@ -0,0 +1,110 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+)
+
+// DeviceType represents a PHPIPAM device type, such as "Switch" or
+// "Router".
+type DeviceType struct {
+	// The device type ID.
+	ID int `json:"id,string,omitempty"`
+
+	// The name of the device type.
+	Name string `json:"name,omitempty"`
+
+	// A detailed description of the device type.
+	Description string `json:"description,omitempty"`
+}
+
+// ListDeviceTypes lists all device types.
+func (c *Controller) ListDeviceTypes() (out []DeviceType, err error) {
+	return c.ListDeviceTypesWithContext(context.Background())
+}
+
+// ListDeviceTypesWithContext is the same as ListDeviceTypes, but takes a
+// context.Context.
+func (c *Controller) ListDeviceTypesWithContext(ctx context.Context) (out []DeviceType, err error) {
+	err = c.SendRequestWithContext(ctx, "GET", "/tools/device_types/", &struct{}{}, &out)
+	return
+}
+
+// CreateDeviceType creates a device type by sending a POST request.
+func (c *Controller) CreateDeviceType(in DeviceType) (message string, err error) {
+	return c.CreateDeviceTypeWithContext(context.Background(), in)
+}
+
+// CreateDeviceTypeWithContext is the same as CreateDeviceType, but takes a
+// context.Context.
+func (c *Controller) CreateDeviceTypeWithContext(ctx context.Context, in DeviceType) (message string, err error) {
+	err = c.SendRequestWithContext(ctx, "POST", "/tools/device_types/", &in, &message)
+	return
+}
+
+// CreateDeviceTypeAndGetID creates a device type by sending a POST request, and
+// returns the ID of the new device type.
+func (c *Controller) CreateDeviceTypeAndGetID(in DeviceType) (id int, err error) {
+	return c.CreateDeviceTypeAndGetIDWithContext(context.Background(), in)
+}
+
+// CreateDeviceTypeAndGetIDWithContext is the same as CreateDeviceTypeAndGetID,
+// but takes a context.Context.
+func (c *Controller) CreateDeviceTypeAndGetIDWithContext(ctx context.Context, in DeviceType) (id int, err error) {
+	id, err = c.Client.CreateResourceWithContext(ctx, "/tools/device_types/", &in)
+	return
+}
+
+// CreateDeviceTypeAndGet creates a device type by sending a POST request, and
+// returns the new device type as read back from the API.
+func (c *Controller) CreateDeviceTypeAndGet(in DeviceType) (out DeviceType, err error) {
+	return c.CreateDeviceTypeAndGetWithContext(context.Background(), in)
+}
+
+// CreateDeviceTypeAndGetWithContext is the same as CreateDeviceTypeAndGet, but
+// takes a context.Context.
+func (c *Controller) CreateDeviceTypeAndGetWithContext(ctx context.Context, in DeviceType) (out DeviceType, err error) {
+	var id int
+	id, err = c.CreateDeviceTypeAndGetIDWithContext(ctx, in)
+	if err != nil {
+		return
+	}
+	out, err = c.GetDeviceTypeByIDWithContext(ctx, id)
+	return
+}
+
+// GetDeviceTypeByID GETs a device type via its ID.
+func (c *Controller) GetDeviceTypeByID(id int) (out DeviceType, err error) {
+	return c.GetDeviceTypeByIDWithContext(context.Background(), id)
+}
+
+// GetDeviceTypeByIDWithContext is the same as GetDeviceTypeByID, but takes a
+// context.Context.
+func (c *Controller) GetDeviceTypeByIDWithContext(ctx context.Context, id int) (out DeviceType, err error) {
+	err = c.SendRequestWithContext(ctx, "GET", fmt.Sprintf("/tools/device_types/%d/", id), &struct{}{}, &out)
+	return
+}
+
+// UpdateDeviceType updates a device type by sending a PATCH request.
+func (c *Controller) UpdateDeviceType(in DeviceType) (message string, err error) {
+	return c.UpdateDeviceTypeWithContext(context.Background(), in)
+}
+
+// UpdateDeviceTypeWithContext is the same as UpdateDeviceType, but takes a
+// context.Context.
+func (c *Controller) UpdateDeviceTypeWithContext(ctx context.Context, in DeviceType) (message string, err error) {
+	err = c.SendRequestWithContext(ctx, "PATCH", "/tools/device_types/", &in, &message)
+	return
+}
+
+// DeleteDeviceType deletes a device type by its ID.
+func (c *Controller) DeleteDeviceType(id int) (message string, err error) {
+	return c.DeleteDeviceTypeWithContext(context.Background(), id)
+}
+
+// DeleteDeviceTypeWithContext is the same as DeleteDeviceType, but takes a
+// context.Context.
+func (c *Controller) DeleteDeviceTypeWithContext(ctx context.Context, id int) (message string, err error) {
+	err = c.SendRequestWithContext(ctx, "DELETE", fmt.Sprintf("/tools/device_types/%d/", id), &struct{}{}, &message)
+	return
+}
@@ -0,0 +1,187 @@
+package tools
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+var testCreateDeviceTypeInput = DeviceType{
+	Name:        "Switch",
+	Description: "Switch",
+}
+
+const testCreateDeviceTypeAndGetIDOutputJSON = `
+{
+  "code": 201,
+  "success": true,
+  "message": "Device type created",
+  "id": "2",
+  "time": 0.005
+}
+`
+
+var testGetDeviceTypeByIDOutputExpected = DeviceType{
+	ID:          2,
+	Name:        "Switch",
+	Description: "Switch",
+}
+
+const testGetDeviceTypeByIDOutputJSON = `
+{
+  "code": 200,
+  "success": true,
+  "data": {
+    "id": "2",
+    "name": "Switch",
+    "description": "Switch"
+  }
+}
+`
+
+var testListDeviceTypesOutputExpected = []DeviceType{
+	DeviceType{
+		ID:          2,
+		Name:        "Switch",
+		Description: "Switch",
+	},
+}
+
+const testListDeviceTypesOutputJSON = `
+{
+  "code": 200,
+  "success": true,
+  "data": [
+    {
+      "id": "2",
+      "name": "Switch",
+      "description": "Switch"
+    }
+  ]
+}
+`
+
+var testUpdateDeviceTypeInput = DeviceType{
+	ID:          2,
+	Description: "foobat",
+}
+
+const testUpdateDeviceTypeOutputExpected = `Device type updated`
+const testUpdateDeviceTypeOutputJSON = `
+{
+  "code": 200,
+  "success": true,
+  "data": "Device type updated"
+}
+`
+
+const testDeleteDeviceTypeOutputExpected = `Device type deleted`
+const testDeleteDeviceTypeOutputJSON = `
+{
+  "code": 200,
+  "success": true,
+  "data": "Device type deleted"
+}
+`
+
+func TestListDeviceTypes(t *testing.T) {
+	ts := httpOKTestServer(testListDeviceTypesOutputJSON)
+	defer ts.Close()
+	sess := fullSessionConfig()
+	sess.Config.Endpoint = ts.URL
+	client := NewController(sess)
+
+	expected := testListDeviceTypesOutputExpected
+	actual, err := client.ListDeviceTypes()
+	if err != nil {
+		t.Fatalf("Bad: %s", err)
+	}
+
+	if !reflect.DeepEqual(expected, actual) {
+		t.Fatalf("Expected %#v, got %#v", expected, actual)
+	}
+}
+
+func TestCreateDeviceTypeAndGet(t *testing.T) {
+	ts := newHTTPTestServer(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Content-Type", "application/json")
+		switch {
+		case r.Method == "POST" && r.URL.Path == "/0123456789abcdefgh/tools/device_types/":
+			http.Error(w, testCreateDeviceTypeAndGetIDOutputJSON, http.StatusCreated)
+		case r.Method == "GET" && r.URL.Path == "/0123456789abcdefgh/tools/device_types/2/":
+			http.Error(w, testGetDeviceTypeByIDOutputJSON, http.StatusOK)
+		default:
+			http.Error(w, "not found", http.StatusNotFound)
+		}
+	})
+	defer ts.Close()
+	sess := fullSessionConfig()
+	sess.Config.Endpoint = ts.URL
+	client := NewController(sess)
+
+	in := testCreateDeviceTypeInput
+	expected := testGetDeviceTypeByIDOutputExpected
+	actual, err := client.CreateDeviceTypeAndGet(in)
+	if err != nil {
+		t.Fatalf("Bad: %s", err)
+	}
+
+	if !reflect.DeepEqual(expected, actual) {
+		t.Fatalf("Expected %#v, got %#v", expected, actual)
+	}
+}
+
+func TestGetDeviceTypeByID(t *testing.T) {
+	ts := httpOKTestServer(testGetDeviceTypeByIDOutputJSON)
+	defer ts.Close()
+	sess := fullSessionConfig()
+	sess.Config.Endpoint = ts.URL
+	client := NewController(sess)
+
+	expected := testGetDeviceTypeByIDOutputExpected
+	actual, err := client.GetDeviceTypeByID(2)
+	if err != nil {
+		t.Fatalf("Bad: %s", err)
+	}
+
+	if !reflect.DeepEqual(expected, actual) {
+		t.Fatalf("Expected %#v, got %#v", expected, actual)
+	}
+}
+
+func TestUpdateDeviceType(t *testing.T) {
+	ts := httpOKTestServer(testUpdateDeviceTypeOutputJSON)
+	defer ts.Close()
+	sess := fullSessionConfig()
+	sess.Config.Endpoint = ts.URL
+	client := NewController(sess)
+
+	in := testUpdateDeviceTypeInput
+	expected := testUpdateDeviceTypeOutputExpected
+	actual, err := client.UpdateDeviceType(in)
+	if err != nil {
+		t.Fatalf("Bad: %s", err)
+	}
+
+	if !reflect.DeepEqual(expected, actual) {
+		t.Fatalf("Expected %#v, got %#v", expected, actual)
+	}
+}
+
+func TestDeleteDeviceType(t *testing.T) {
+	ts := httpOKTestServer(testDeleteDeviceTypeOutputJSON)
+	defer ts.Close()
+	sess := fullSessionConfig()
+	sess.Config.Endpoint = ts.URL
+	client := NewController(sess)
+
+	expected := testDeleteDeviceTypeOutputExpected
+	actual, err := client.DeleteDeviceType(2)
+	if err != nil {
+		t.Fatalf("Bad: %s", err)
+	}
+
+	if !reflect.DeepEqual(expected, actual) {
+		t.Fatalf("Expected %#v, got %#v", expected, actual)
+	}
+}
@@ -0,0 +1,118 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+)
+
+// Location represents a PHPIPAM location.
+type Location struct {
+	// The location ID.
+	ID int `json:"id,string,omitempty"`
+
+	// The name of the location.
+	Name string `json:"name,omitempty"`
+
+	// A detailed description of the location.
+	Description string `json:"description,omitempty"`
+
+	// The street address of the location.
+	Address string `json:"address,omitempty"`
+
+	// The latitude of the location.
+	Latitude string `json:"lat,omitempty"`
+
+	// The longitude of the location.
+	Longitude string `json:"long,omitempty"`
+}
+
+// ListLocations lists all locations.
+func (c *Controller) ListLocations() (out []Location, err error) {
+	return c.ListLocationsWithContext(context.Background())
+}
+
+// ListLocationsWithContext is the same as ListLocations, but takes a
+// context.Context.
+func (c *Controller) ListLocationsWithContext(ctx context.Context) (out []Location, err error) {
+	err = c.SendRequestWithContext(ctx, "GET", "/tools/locations/", &struct{}{}, &out)
+	return
+}
+
+// CreateLocation creates a location by sending a POST request.
+func (c *Controller) CreateLocation(in Location) (message string, err error) {
+	return c.CreateLocationWithContext(context.Background(), in)
+}
+
+// CreateLocationWithContext is the same as CreateLocation, but takes a
+// context.Context.
+func (c *Controller) CreateLocationWithContext(ctx context.Context, in Location) (message string, err error) {
+	err = c.SendRequestWithContext(ctx, "POST", "/tools/locations/", &in, &message)
+	return
+}
+
+// CreateLocationAndGetID creates a location by sending a POST request, and
+// returns the ID of the new location.
+func (c *Controller) CreateLocationAndGetID(in Location) (id int, err error) {
+	return c.CreateLocationAndGetIDWithContext(context.Background(), in)
+}
+
+// CreateLocationAndGetIDWithContext is the same as CreateLocationAndGetID, but
+// takes a context.Context.
+func (c *Controller) CreateLocationAndGetIDWithContext(ctx context.Context, in Location) (id int, err error) {
+	id, err = c.Client.CreateResourceWithContext(ctx, "/tools/locations/", &in)
+	return
+}
+
+// CreateLocationAndGet creates a location by sending a POST request, and
+// returns the new location as read back from the API.
+func (c *Controller) CreateLocationAndGet(in Location) (out Location, err error) {
+	return c.CreateLocationAndGetWithContext(context.Background(), in)
+}
+
+// CreateLocationAndGetWithContext is the same as CreateLocationAndGet, but
+// takes a context.Context.
+func (c *Controller) CreateLocationAndGetWithContext(ctx context.Context, in Location) (out Location, err error) {
+	var id int
+	id, err = c.CreateLocationAndGetIDWithContext(ctx, in)
+	if err != nil {
+		return
+	}
+	out, err = c.GetLocationByIDWithContext(ctx, id)
+	return
+}
+
+// GetLocationByID GETs a location via its ID.
+func (c *Controller) GetLocationByID(id int) (out Location, err error) {
+	return c.GetLocationByIDWithContext(context.Background(), id)
+}
+
+// GetLocationByIDWithContext is the same as GetLocationByID, but takes a
+// context.Context.
+func (c *Controller) GetLocationByIDWithContext(ctx context.Context, id int) (out Location, err error) {
+	err = c.SendRequestWithContext(ctx, "GET", fmt.Sprintf("/tools/locations/%d/", id), &struct{}{}, &out)
+	return
+}
+
+// UpdateLocation updates a location by sending a PATCH request.
+func (c *Controller) UpdateLocation(in Location) (message string, err error) {
+	return c.UpdateLocationWithContext(context.Background(), in)
+}
+
+// UpdateLocationWithContext is the same as UpdateLocation, but takes a
+// context.Context.
+func (c *Controller) UpdateLocationWithContext(ctx context.Context, in Location) (message string, err error) {
+	err = c.SendRequestWithContext(ctx, "PATCH", "/tools/locations/", &in, &message)
+	return
+}
+
+// DeleteLocation deletes a location by its ID.
+func (c *Controller) DeleteLocation(id int) (message string, err error) {
+	return c.DeleteLocationWithContext(context.Background(), id)
+}
+
+// DeleteLocationWithContext is the same as DeleteLocation, but takes a
+// context.Context.
+func (c *Controller) DeleteLocationWithContext(ctx context.Context, id int) (message string, err error) {
+	err = c.SendRequestWithContext(ctx, "DELETE", fmt.Sprintf("/tools/locations/%d/", id), &struct{}{}, &message)
+	return
+}
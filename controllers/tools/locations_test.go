@@ -0,0 +1,199 @@
+package tools
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+var testCreateLocationInput = Location{
+	Name:        "dc1",
+	Description: "Primary data center",
+}
+
+const testCreateLocationAndGetIDOutputJSON = `
+{
+  "code": 201,
+  "success": true,
+  "message": "Location created",
+  "id": "2",
+  "time": 0.005
+}
+`
+
+var testGetLocationByIDOutputExpected = Location{
+	ID:          2,
+	Name:        "dc1",
+	Description: "Primary data center",
+	Address:     "1 Example Way",
+	Latitude:    "49.2827",
+	Longitude:   "-123.1207",
+}
+
+const testGetLocationByIDOutputJSON = `
+{
+  "code": 200,
+  "success": true,
+  "data": {
+    "id": "2",
+    "name": "dc1",
+    "description": "Primary data center",
+    "address": "1 Example Way",
+    "lat": "49.2827",
+    "long": "-123.1207"
+  }
+}
+`
+
+var testListLocationsOutputExpected = []Location{
+	Location{
+		ID:          2,
+		Name:        "dc1",
+		Description: "Primary data center",
+		Address:     "1 Example Way",
+		Latitude:    "49.2827",
+		Longitude:   "-123.1207",
+	},
+}
+
+const testListLocationsOutputJSON = `
+{
+  "code": 200,
+  "success": true,
+  "data": [
+    {
+      "id": "2",
+      "name": "dc1",
+      "description": "Primary data center",
+      "address": "1 Example Way",
+      "lat": "49.2827",
+      "long": "-123.1207"
+    }
+  ]
+}
+`
+
+var testUpdateLocationInput = Location{
+	ID:          2,
+	Description: "foobat",
+}
+
+const testUpdateLocationOutputExpected = `Location updated`
+const testUpdateLocationOutputJSON = `
+{
+  "code": 200,
+  "success": true,
+  "data": "Location updated"
+}
+`
+
+const testDeleteLocationOutputExpected = `Location deleted`
+const testDeleteLocationOutputJSON = `
+{
+  "code": 200,
+  "success": true,
+  "data": "Location deleted"
+}
+`
+
+func TestListLocations(t *testing.T) {
+	ts := httpOKTestServer(testListLocationsOutputJSON)
+	defer ts.Close()
+	sess := fullSessionConfig()
+	sess.Config.Endpoint = ts.URL
+	client := NewController(sess)
+
+	expected := testListLocationsOutputExpected
+	actual, err := client.ListLocations()
+	if err != nil {
+		t.Fatalf("Bad: %s", err)
+	}
+
+	if !reflect.DeepEqual(expected, actual) {
+		t.Fatalf("Expected %#v, got %#v", expected, actual)
+	}
+}
+
+func TestCreateLocationAndGet(t *testing.T) {
+	ts := newHTTPTestServer(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Content-Type", "application/json")
+		switch {
+		case r.Method == "POST" && r.URL.Path == "/0123456789abcdefgh/tools/locations/":
+			http.Error(w, testCreateLocationAndGetIDOutputJSON, http.StatusCreated)
+		case r.Method == "GET" && r.URL.Path == "/0123456789abcdefgh/tools/locations/2/":
+			http.Error(w, testGetLocationByIDOutputJSON, http.StatusOK)
+		default:
+			http.Error(w, "not found", http.StatusNotFound)
+		}
+	})
+	defer ts.Close()
+	sess := fullSessionConfig()
+	sess.Config.Endpoint = ts.URL
+	client := NewController(sess)
+
+	in := testCreateLocationInput
+	expected := testGetLocationByIDOutputExpected
+	actual, err := client.CreateLocationAndGet(in)
+	if err != nil {
+		t.Fatalf("Bad: %s", err)
+	}
+
+	if !reflect.DeepEqual(expected, actual) {
+		t.Fatalf("Expected %#v, got %#v", expected, actual)
+	}
+}
+
+func TestGetLocationByID(t *testing.T) {
+	ts := httpOKTestServer(testGetLocationByIDOutputJSON)
+	defer ts.Close()
+	sess := fullSessionConfig()
+	sess.Config.Endpoint = ts.URL
+	client := NewController(sess)
+
+	expected := testGetLocationByIDOutputExpected
+	actual, err := client.GetLocationByID(2)
+	if err != nil {
+		t.Fatalf("Bad: %s", err)
+	}
+
+	if !reflect.DeepEqual(expected, actual) {
+		t.Fatalf("Expected %#v, got %#v", expected, actual)
+	}
+}
+
+func TestUpdateLocation(t *testing.T) {
+	ts := httpOKTestServer(testUpdateLocationOutputJSON)
+	defer ts.Close()
+	sess := fullSessionConfig()
+	sess.Config.Endpoint = ts.URL
+	client := NewController(sess)
+
+	in := testUpdateLocationInput
+	expected := testUpdateLocationOutputExpected
+	actual, err := client.UpdateLocation(in)
+	if err != nil {
+		t.Fatalf("Bad: %s", err)
+	}
+
+	if !reflect.DeepEqual(expected, actual) {
+		t.Fatalf("Expected %#v, got %#v", expected, actual)
+	}
+}
+
+func TestDeleteLocation(t *testing.T) {
+	ts := httpOKTestServer(testDeleteLocationOutputJSON)
+	defer ts.Close()
+	sess := fullSessionConfig()
+	sess.Config.Endpoint = ts.URL
+	client := NewController(sess)
+
+	expected := testDeleteLocationOutputExpected
+	actual, err := client.DeleteLocation(2)
+	if err != nil {
+		t.Fatalf("Bad: %s", err)
+	}
+
+	if !reflect.DeepEqual(expected, actual) {
+		t.Fatalf("Expected %#v, got %#v", expected, actual)
+	}
+}
@@ -0,0 +1,120 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+)
+
+// Nameserver represents a PHPIPAM nameserver set.
+type Nameserver struct {
+	// The nameserver set ID.
+	ID int `json:"id,string,omitempty"`
+
+	// The name of the nameserver set.
+	Name string `json:"name,omitempty"`
+
+	// A semicolon-separated list of the nameservers in this set (i.e.
+	// "8.8.8.8;8.8.4.4").
+	Nameservers string `json:"namesrv1,omitempty"`
+
+	// A detailed description of the nameserver set.
+	Description string `json:"description,omitempty"`
+
+	// A semicolon-separated list of the IDs of the sections that this
+	// nameserver set can be used in (i.e. "1;2;3").
+	Sections string `json:"permissions,omitempty"`
+
+	// The date of the last edit to this resource.
+	EditDate string `json:"editDate,omitempty"`
+}
+
+// ListNameservers lists all nameserver sets.
+func (c *Controller) ListNameservers() (out []Nameserver, err error) {
+	return c.ListNameserversWithContext(context.Background())
+}
+
+// ListNameserversWithContext is the same as ListNameservers, but takes a
+// context.Context.
+func (c *Controller) ListNameserversWithContext(ctx context.Context) (out []Nameserver, err error) {
+	err = c.SendRequestWithContext(ctx, "GET", "/tools/nameservers/", &struct{}{}, &out)
+	return
+}
+
+// CreateNameserver creates a nameserver set by sending a POST request.
+func (c *Controller) CreateNameserver(in Nameserver) (message string, err error) {
+	return c.CreateNameserverWithContext(context.Background(), in)
+}
+
+// CreateNameserverWithContext is the same as CreateNameserver, but takes a
+// context.Context.
+func (c *Controller) CreateNameserverWithContext(ctx context.Context, in Nameserver) (message string, err error) {
+	err = c.SendRequestWithContext(ctx, "POST", "/tools/nameservers/", &in, &message)
+	return
+}
+
+// CreateNameserverAndGetID creates a nameserver set by sending a POST request,
+// and returns the ID of the new nameserver set.
+func (c *Controller) CreateNameserverAndGetID(in Nameserver) (id int, err error) {
+	return c.CreateNameserverAndGetIDWithContext(context.Background(), in)
+}
+
+// CreateNameserverAndGetIDWithContext is the same as CreateNameserverAndGetID,
+// but takes a context.Context.
+func (c *Controller) CreateNameserverAndGetIDWithContext(ctx context.Context, in Nameserver) (id int, err error) {
+	id, err = c.Client.CreateResourceWithContext(ctx, "/tools/nameservers/", &in)
+	return
+}
+
+// CreateNameserverAndGet creates a nameserver set by sending a POST request,
+// and returns the new nameserver set as read back from the API.
+func (c *Controller) CreateNameserverAndGet(in Nameserver) (out Nameserver, err error) {
+	return c.CreateNameserverAndGetWithContext(context.Background(), in)
+}
+
+// CreateNameserverAndGetWithContext is the same as CreateNameserverAndGet, but
+// takes a context.Context.
+func (c *Controller) CreateNameserverAndGetWithContext(ctx context.Context, in Nameserver) (out Nameserver, err error) {
+	var id int
+	id, err = c.CreateNameserverAndGetIDWithContext(ctx, in)
+	if err != nil {
+		return
+	}
+	out, err = c.GetNameserverByIDWithContext(ctx, id)
+	return
+}
+
+// GetNameserverByID GETs a nameserver set via its ID.
+func (c *Controller) GetNameserverByID(id int) (out Nameserver, err error) {
+	return c.GetNameserverByIDWithContext(context.Background(), id)
+}
+
+// GetNameserverByIDWithContext is the same as GetNameserverByID, but takes a
+// context.Context.
+func (c *Controller) GetNameserverByIDWithContext(ctx context.Context, id int) (out Nameserver, err error) {
+	err = c.SendRequestWithContext(ctx, "GET", fmt.Sprintf("/tools/nameservers/%d/", id), &struct{}{}, &out)
+	return
+}
+
+// UpdateNameserver updates a nameserver set by sending a PATCH request.
+func (c *Controller) UpdateNameserver(in Nameserver) (message string, err error) {
+	return c.UpdateNameserverWithContext(context.Background(), in)
+}
+
+// UpdateNameserverWithContext is the same as UpdateNameserver, but takes a
+// context.Context.
+func (c *Controller) UpdateNameserverWithContext(ctx context.Context, in Nameserver) (message string, err error) {
+	err = c.SendRequestWithContext(ctx, "PATCH", "/tools/nameservers/", &in, &message)
+	return
+}
+
+// DeleteNameserver deletes a nameserver set by its ID.
+func (c *Controller) DeleteNameserver(id int) (message string, err error) {
+	return c.DeleteNameserverWithContext(context.Background(), id)
+}
+
+// DeleteNameserverWithContext is the same as DeleteNameserver, but takes a
+// context.Context.
+func (c *Controller) DeleteNameserverWithContext(ctx context.Context, id int) (message string, err error) {
+	err = c.SendRequestWithContext(ctx, "DELETE", fmt.Sprintf("/tools/nameservers/%d/", id), &struct{}{}, &message)
+	return
+}
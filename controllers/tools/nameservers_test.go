@@ -0,0 +1,197 @@
+package tools
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+var testCreateNameserverInput = Nameserver{
+	Name:        "Google NS",
+	Nameservers: "8.8.8.8;8.8.4.4",
+}
+
+const testCreateNameserverAndGetIDOutputJSON = `
+{
+  "code": 201,
+  "success": true,
+  "message": "Nameserver set created",
+  "id": "2",
+  "time": 0.005
+}
+`
+
+var testGetNameserverByIDOutputExpected = Nameserver{
+	ID:          2,
+	Name:        "Google NS",
+	Nameservers: "8.8.8.8;8.8.4.4",
+	Description: "Google public nameservers",
+	Sections:    "1;2",
+}
+
+const testGetNameserverByIDOutputJSON = `
+{
+  "code": 200,
+  "success": true,
+  "data": {
+    "id": "2",
+    "name": "Google NS",
+    "namesrv1": "8.8.8.8;8.8.4.4",
+    "description": "Google public nameservers",
+    "permissions": "1;2",
+    "editDate": null
+  }
+}
+`
+
+var testListNameserversOutputExpected = []Nameserver{
+	Nameserver{
+		ID:          2,
+		Name:        "Google NS",
+		Nameservers: "8.8.8.8;8.8.4.4",
+		Description: "Google public nameservers",
+		Sections:    "1;2",
+	},
+}
+
+const testListNameserversOutputJSON = `
+{
+  "code": 200,
+  "success": true,
+  "data": [
+    {
+      "id": "2",
+      "name": "Google NS",
+      "namesrv1": "8.8.8.8;8.8.4.4",
+      "description": "Google public nameservers",
+      "permissions": "1;2",
+      "editDate": null
+    }
+  ]
+}
+`
+
+var testUpdateNameserverInput = Nameserver{
+	ID:          2,
+	Description: "foobat",
+}
+
+const testUpdateNameserverOutputExpected = `Nameserver set updated`
+const testUpdateNameserverOutputJSON = `
+{
+  "code": 200,
+  "success": true,
+  "data": "Nameserver set updated"
+}
+`
+
+const testDeleteNameserverOutputExpected = `Nameserver set deleted`
+const testDeleteNameserverOutputJSON = `
+{
+  "code": 200,
+  "success": true,
+  "data": "Nameserver set deleted"
+}
+`
+
+func TestListNameservers(t *testing.T) {
+	ts := httpOKTestServer(testListNameserversOutputJSON)
+	defer ts.Close()
+	sess := fullSessionConfig()
+	sess.Config.Endpoint = ts.URL
+	client := NewController(sess)
+
+	expected := testListNameserversOutputExpected
+	actual, err := client.ListNameservers()
+	if err != nil {
+		t.Fatalf("Bad: %s", err)
+	}
+
+	if !reflect.DeepEqual(expected, actual) {
+		t.Fatalf("Expected %#v, got %#v", expected, actual)
+	}
+}
+
+func TestCreateNameserverAndGet(t *testing.T) {
+	ts := newHTTPTestServer(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Content-Type", "application/json")
+		switch {
+		case r.Method == "POST" && r.URL.Path == "/0123456789abcdefgh/tools/nameservers/":
+			http.Error(w, testCreateNameserverAndGetIDOutputJSON, http.StatusCreated)
+		case r.Method == "GET" && r.URL.Path == "/0123456789abcdefgh/tools/nameservers/2/":
+			http.Error(w, testGetNameserverByIDOutputJSON, http.StatusOK)
+		default:
+			http.Error(w, "not found", http.StatusNotFound)
+		}
+	})
+	defer ts.Close()
+	sess := fullSessionConfig()
+	sess.Config.Endpoint = ts.URL
+	client := NewController(sess)
+
+	in := testCreateNameserverInput
+	expected := testGetNameserverByIDOutputExpected
+	actual, err := client.CreateNameserverAndGet(in)
+	if err != nil {
+		t.Fatalf("Bad: %s", err)
+	}
+
+	if !reflect.DeepEqual(expected, actual) {
+		t.Fatalf("Expected %#v, got %#v", expected, actual)
+	}
+}
+
+func TestGetNameserverByID(t *testing.T) {
+	ts := httpOKTestServer(testGetNameserverByIDOutputJSON)
+	defer ts.Close()
+	sess := fullSessionConfig()
+	sess.Config.Endpoint = ts.URL
+	client := NewController(sess)
+
+	expected := testGetNameserverByIDOutputExpected
+	actual, err := client.GetNameserverByID(2)
+	if err != nil {
+		t.Fatalf("Bad: %s", err)
+	}
+
+	if !reflect.DeepEqual(expected, actual) {
+		t.Fatalf("Expected %#v, got %#v", expected, actual)
+	}
+}
+
+func TestUpdateNameserver(t *testing.T) {
+	ts := httpOKTestServer(testUpdateNameserverOutputJSON)
+	defer ts.Close()
+	sess := fullSessionConfig()
+	sess.Config.Endpoint = ts.URL
+	client := NewController(sess)
+
+	in := testUpdateNameserverInput
+	expected := testUpdateNameserverOutputExpected
+	actual, err := client.UpdateNameserver(in)
+	if err != nil {
+		t.Fatalf("Bad: %s", err)
+	}
+
+	if !reflect.DeepEqual(expected, actual) {
+		t.Fatalf("Expected %#v, got %#v", expected, actual)
+	}
+}
+
+func TestDeleteNameserver(t *testing.T) {
+	ts := httpOKTestServer(testDeleteNameserverOutputJSON)
+	defer ts.Close()
+	sess := fullSessionConfig()
+	sess.Config.Endpoint = ts.URL
+	client := NewController(sess)
+
+	expected := testDeleteNameserverOutputExpected
+	actual, err := client.DeleteNameserver(2)
+	if err != nil {
+		t.Fatalf("Bad: %s", err)
+	}
+
+	if !reflect.DeepEqual(expected, actual) {
+		t.Fatalf("Expected %#v, got %#v", expected, actual)
+	}
+}
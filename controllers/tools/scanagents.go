@@ -0,0 +1,120 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+)
+
+// ScanAgent represents a PHPIPAM scan agent.
+type ScanAgent struct {
+	// The scan agent ID.
+	ID int `json:"id,string,omitempty"`
+
+	// The name of the scan agent.
+	Name string `json:"name,omitempty"`
+
+	// A detailed description of the scan agent.
+	Description string `json:"description,omitempty"`
+
+	// The type of the scan agent. This is "mysql" for the built-in agent and
+	// remote agents that connect to the database directly, or "api" for
+	// remote agents that connect through the API.
+	Type string `json:"type,omitempty"`
+
+	// The code the scan agent uses to authenticate.
+	Code string `json:"code,omitempty"`
+
+	// The time the scan agent last checked in.
+	LastAccess string `json:"last_access,omitempty"`
+}
+
+// ListScanAgents lists all scan agents.
+func (c *Controller) ListScanAgents() (out []ScanAgent, err error) {
+	return c.ListScanAgentsWithContext(context.Background())
+}
+
+// ListScanAgentsWithContext is the same as ListScanAgents, but takes a
+// context.Context.
+func (c *Controller) ListScanAgentsWithContext(ctx context.Context) (out []ScanAgent, err error) {
+	err = c.SendRequestWithContext(ctx, "GET", "/tools/scanagents/", &struct{}{}, &out)
+	return
+}
+
+// CreateScanAgent creates a scan agent by sending a POST request.
+func (c *Controller) CreateScanAgent(in ScanAgent) (message string, err error) {
+	return c.CreateScanAgentWithContext(context.Background(), in)
+}
+
+// CreateScanAgentWithContext is the same as CreateScanAgent, but takes a
+// context.Context.
+func (c *Controller) CreateScanAgentWithContext(ctx context.Context, in ScanAgent) (message string, err error) {
+	err = c.SendRequestWithContext(ctx, "POST", "/tools/scanagents/", &in, &message)
+	return
+}
+
+// CreateScanAgentAndGetID creates a scan agent by sending a POST request, and
+// returns the ID of the new scan agent.
+func (c *Controller) CreateScanAgentAndGetID(in ScanAgent) (id int, err error) {
+	return c.CreateScanAgentAndGetIDWithContext(context.Background(), in)
+}
+
+// CreateScanAgentAndGetIDWithContext is the same as CreateScanAgentAndGetID,
+// but takes a context.Context.
+func (c *Controller) CreateScanAgentAndGetIDWithContext(ctx context.Context, in ScanAgent) (id int, err error) {
+	id, err = c.Client.CreateResourceWithContext(ctx, "/tools/scanagents/", &in)
+	return
+}
+
+// CreateScanAgentAndGet creates a scan agent by sending a POST request, and
+// returns the new scan agent as read back from the API.
+func (c *Controller) CreateScanAgentAndGet(in ScanAgent) (out ScanAgent, err error) {
+	return c.CreateScanAgentAndGetWithContext(context.Background(), in)
+}
+
+// CreateScanAgentAndGetWithContext is the same as CreateScanAgentAndGet, but
+// takes a context.Context.
+func (c *Controller) CreateScanAgentAndGetWithContext(ctx context.Context, in ScanAgent) (out ScanAgent, err error) {
+	var id int
+	id, err = c.CreateScanAgentAndGetIDWithContext(ctx, in)
+	if err != nil {
+		return
+	}
+	out, err = c.GetScanAgentByIDWithContext(ctx, id)
+	return
+}
+
+// GetScanAgentByID GETs a scan agent via its ID.
+func (c *Controller) GetScanAgentByID(id int) (out ScanAgent, err error) {
+	return c.GetScanAgentByIDWithContext(context.Background(), id)
+}
+
+// GetScanAgentByIDWithContext is the same as GetScanAgentByID, but takes a
+// context.Context.
+func (c *Controller) GetScanAgentByIDWithContext(ctx context.Context, id int) (out ScanAgent, err error) {
+	err = c.SendRequestWithContext(ctx, "GET", fmt.Sprintf("/tools/scanagents/%d/", id), &struct{}{}, &out)
+	return
+}
+
+// UpdateScanAgent updates a scan agent by sending a PATCH request.
+func (c *Controller) UpdateScanAgent(in ScanAgent) (message string, err error) {
+	return c.UpdateScanAgentWithContext(context.Background(), in)
+}
+
+// UpdateScanAgentWithContext is the same as UpdateScanAgent, but takes a
+// context.Context.
+func (c *Controller) UpdateScanAgentWithContext(ctx context.Context, in ScanAgent) (message string, err error) {
+	err = c.SendRequestWithContext(ctx, "PATCH", "/tools/scanagents/", &in, &message)
+	return
+}
+
+// DeleteScanAgent deletes a scan agent by its ID.
+func (c *Controller) DeleteScanAgent(id int) (message string, err error) {
+	return c.DeleteScanAgentWithContext(context.Background(), id)
+}
+
+// DeleteScanAgentWithContext is the same as DeleteScanAgent, but takes a
+// context.Context.
+func (c *Controller) DeleteScanAgentWithContext(ctx context.Context, id int) (message string, err error) {
+	err = c.SendRequestWithContext(ctx, "DELETE", fmt.Sprintf("/tools/scanagents/%d/", id), &struct{}{}, &message)
+	return
+}
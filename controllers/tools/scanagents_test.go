@@ -0,0 +1,200 @@
+package tools
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+var testCreateScanAgentInput = ScanAgent{
+	Name:        "remote1",
+	Description: "Remote scan agent",
+	Type:        "api",
+}
+
+const testCreateScanAgentAndGetIDOutputJSON = `
+{
+  "code": 201,
+  "success": true,
+  "message": "Scan agent created",
+  "id": "2",
+  "time": 0.005
+}
+`
+
+var testGetScanAgentByIDOutputExpected = ScanAgent{
+	ID:          2,
+	Name:        "remote1",
+	Description: "Remote scan agent",
+	Type:        "api",
+	Code:        "abcdefgh0123456789",
+	LastAccess:  "2017-01-01 00:00:00",
+}
+
+const testGetScanAgentByIDOutputJSON = `
+{
+  "code": 200,
+  "success": true,
+  "data": {
+    "id": "2",
+    "name": "remote1",
+    "description": "Remote scan agent",
+    "type": "api",
+    "code": "abcdefgh0123456789",
+    "last_access": "2017-01-01 00:00:00"
+  }
+}
+`
+
+var testListScanAgentsOutputExpected = []ScanAgent{
+	ScanAgent{
+		ID:          2,
+		Name:        "remote1",
+		Description: "Remote scan agent",
+		Type:        "api",
+		Code:        "abcdefgh0123456789",
+		LastAccess:  "2017-01-01 00:00:00",
+	},
+}
+
+const testListScanAgentsOutputJSON = `
+{
+  "code": 200,
+  "success": true,
+  "data": [
+    {
+      "id": "2",
+      "name": "remote1",
+      "description": "Remote scan agent",
+      "type": "api",
+      "code": "abcdefgh0123456789",
+      "last_access": "2017-01-01 00:00:00"
+    }
+  ]
+}
+`
+
+var testUpdateScanAgentInput = ScanAgent{
+	ID:          2,
+	Description: "foobat",
+}
+
+const testUpdateScanAgentOutputExpected = `Scan agent updated`
+const testUpdateScanAgentOutputJSON = `
+{
+  "code": 200,
+  "success": true,
+  "data": "Scan agent updated"
+}
+`
+
+const testDeleteScanAgentOutputExpected = `Scan agent deleted`
+const testDeleteScanAgentOutputJSON = `
+{
+  "code": 200,
+  "success": true,
+  "data": "Scan agent deleted"
+}
+`
+
+func TestListScanAgents(t *testing.T) {
+	ts := httpOKTestServer(testListScanAgentsOutputJSON)
+	defer ts.Close()
+	sess := fullSessionConfig()
+	sess.Config.Endpoint = ts.URL
+	client := NewController(sess)
+
+	expected := testListScanAgentsOutputExpected
+	actual, err := client.ListScanAgents()
+	if err != nil {
+		t.Fatalf("Bad: %s", err)
+	}
+
+	if !reflect.DeepEqual(expected, actual) {
+		t.Fatalf("Expected %#v, got %#v", expected, actual)
+	}
+}
+
+func TestCreateScanAgentAndGet(t *testing.T) {
+	ts := newHTTPTestServer(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Content-Type", "application/json")
+		switch {
+		case r.Method == "POST" && r.URL.Path == "/0123456789abcdefgh/tools/scanagents/":
+			http.Error(w, testCreateScanAgentAndGetIDOutputJSON, http.StatusCreated)
+		case r.Method == "GET" && r.URL.Path == "/0123456789abcdefgh/tools/scanagents/2/":
+			http.Error(w, testGetScanAgentByIDOutputJSON, http.StatusOK)
+		default:
+			http.Error(w, "not found", http.StatusNotFound)
+		}
+	})
+	defer ts.Close()
+	sess := fullSessionConfig()
+	sess.Config.Endpoint = ts.URL
+	client := NewController(sess)
+
+	in := testCreateScanAgentInput
+	expected := testGetScanAgentByIDOutputExpected
+	actual, err := client.CreateScanAgentAndGet(in)
+	if err != nil {
+		t.Fatalf("Bad: %s", err)
+	}
+
+	if !reflect.DeepEqual(expected, actual) {
+		t.Fatalf("Expected %#v, got %#v", expected, actual)
+	}
+}
+
+func TestGetScanAgentByID(t *testing.T) {
+	ts := httpOKTestServer(testGetScanAgentByIDOutputJSON)
+	defer ts.Close()
+	sess := fullSessionConfig()
+	sess.Config.Endpoint = ts.URL
+	client := NewController(sess)
+
+	expected := testGetScanAgentByIDOutputExpected
+	actual, err := client.GetScanAgentByID(2)
+	if err != nil {
+		t.Fatalf("Bad: %s", err)
+	}
+
+	if !reflect.DeepEqual(expected, actual) {
+		t.Fatalf("Expected %#v, got %#v", expected, actual)
+	}
+}
+
+func TestUpdateScanAgent(t *testing.T) {
+	ts := httpOKTestServer(testUpdateScanAgentOutputJSON)
+	defer ts.Close()
+	sess := fullSessionConfig()
+	sess.Config.Endpoint = ts.URL
+	client := NewController(sess)
+
+	in := testUpdateScanAgentInput
+	expected := testUpdateScanAgentOutputExpected
+	actual, err := client.UpdateScanAgent(in)
+	if err != nil {
+		t.Fatalf("Bad: %s", err)
+	}
+
+	if !reflect.DeepEqual(expected, actual) {
+		t.Fatalf("Expected %#v, got %#v", expected, actual)
+	}
+}
+
+func TestDeleteScanAgent(t *testing.T) {
+	ts := httpOKTestServer(testDeleteScanAgentOutputJSON)
+	defer ts.Close()
+	sess := fullSessionConfig()
+	sess.Config.Endpoint = ts.URL
+	client := NewController(sess)
+
+	expected := testDeleteScanAgentOutputExpected
+	actual, err := client.DeleteScanAgent(2)
+	if err != nil {
+		t.Fatalf("Bad: %s", err)
+	}
+
+	if !reflect.DeepEqual(expected, actual) {
+		t.Fatalf("Expected %#v, got %#v", expected, actual)
+	}
+}
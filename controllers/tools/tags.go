@@ -0,0 +1,123 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/paybyphone/phpipam-sdk-go/phpipam"
+)
+
+// Tag represents a PHPIPAM IP address tag, such as "Used" or "Reserved".
+type Tag struct {
+	// The tag ID.
+	ID int `json:"id,string,omitempty"`
+
+	// The name of the tag.
+	Type string `json:"type,omitempty"`
+
+	// true if the tag is shown in the address list.
+	ShowTag phpipam.BoolIntString `json:"showtag,omitempty"`
+
+	// The background color of the tag, as a HTML color code (i.e. "#ffffff").
+	BGColor string `json:"bgcolor,omitempty"`
+
+	// The foreground color of the tag, as a HTML color code.
+	FGColor string `json:"fgcolor,omitempty"`
+
+	// Whether or not ranges of addresses with this tag are compressed in the
+	// address list. Either "Yes" or "No".
+	Compress string `json:"compress,omitempty"`
+
+	// Whether or not the tag is locked from modification. Locked tags are
+	// system tags. Either "Yes" or "No".
+	Locked string `json:"locked,omitempty"`
+
+	// true if addresses with this tag are updated by network scans.
+	UpdateTag phpipam.BoolIntString `json:"updateTag,omitempty"`
+}
+
+// ListTags lists all tags.
+func (c *Controller) ListTags() (out []Tag, err error) {
+	return c.ListTagsWithContext(context.Background())
+}
+
+// ListTagsWithContext is the same as ListTags, but takes a context.Context.
+func (c *Controller) ListTagsWithContext(ctx context.Context) (out []Tag, err error) {
+	err = c.SendRequestWithContext(ctx, "GET", "/tools/tags/", &struct{}{}, &out)
+	return
+}
+
+// CreateTag creates a tag by sending a POST request.
+func (c *Controller) CreateTag(in Tag) (message string, err error) {
+	return c.CreateTagWithContext(context.Background(), in)
+}
+
+// CreateTagWithContext is the same as CreateTag, but takes a context.Context.
+func (c *Controller) CreateTagWithContext(ctx context.Context, in Tag) (message string, err error) {
+	err = c.SendRequestWithContext(ctx, "POST", "/tools/tags/", &in, &message)
+	return
+}
+
+// CreateTagAndGetID creates a tag by sending a POST request, and returns the ID
+// of the new tag.
+func (c *Controller) CreateTagAndGetID(in Tag) (id int, err error) {
+	return c.CreateTagAndGetIDWithContext(context.Background(), in)
+}
+
+// CreateTagAndGetIDWithContext is the same as CreateTagAndGetID, but takes a
+// context.Context.
+func (c *Controller) CreateTagAndGetIDWithContext(ctx context.Context, in Tag) (id int, err error) {
+	id, err = c.Client.CreateResourceWithContext(ctx, "/tools/tags/", &in)
+	return
+}
+
+// CreateTagAndGet creates a tag by sending a POST request, and returns the new
+// tag as read back from the API.
+func (c *Controller) CreateTagAndGet(in Tag) (out Tag, err error) {
+	return c.CreateTagAndGetWithContext(context.Background(), in)
+}
+
+// CreateTagAndGetWithContext is the same as CreateTagAndGet, but takes a
+// context.Context.
+func (c *Controller) CreateTagAndGetWithContext(ctx context.Context, in Tag) (out Tag, err error) {
+	var id int
+	id, err = c.CreateTagAndGetIDWithContext(ctx, in)
+	if err != nil {
+		return
+	}
+	out, err = c.GetTagByIDWithContext(ctx, id)
+	return
+}
+
+// GetTagByID GETs a tag via its ID.
+func (c *Controller) GetTagByID(id int) (out Tag, err error) {
+	return c.GetTagByIDWithContext(context.Background(), id)
+}
+
+// GetTagByIDWithContext is the same as GetTagByID, but takes a context.Context.
+func (c *Controller) GetTagByIDWithContext(ctx context.Context, id int) (out Tag, err error) {
+	err = c.SendRequestWithContext(ctx, "GET", fmt.Sprintf("/tools/tags/%d/", id), &struct{}{}, &out)
+	return
+}
+
+// UpdateTag updates a tag by sending a PATCH request.
+func (c *Controller) UpdateTag(in Tag) (message string, err error) {
+	return c.UpdateTagWithContext(context.Background(), in)
+}
+
+// UpdateTagWithContext is the same as UpdateTag, but takes a context.Context.
+func (c *Controller) UpdateTagWithContext(ctx context.Context, in Tag) (message string, err error) {
+	err = c.SendRequestWithContext(ctx, "PATCH", "/tools/tags/", &in, &message)
+	return
+}
+
+// DeleteTag deletes a tag by its ID.
+func (c *Controller) DeleteTag(id int) (message string, err error) {
+	return c.DeleteTagWithContext(context.Background(), id)
+}
+
+// DeleteTagWithContext is the same as DeleteTag, but takes a context.Context.
+func (c *Controller) DeleteTagWithContext(ctx context.Context, id int) (message string, err error) {
+	err = c.SendRequestWithContext(ctx, "DELETE", fmt.Sprintf("/tools/tags/%d/", id), &struct{}{}, &message)
+	return
+}
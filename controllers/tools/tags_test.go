@@ -0,0 +1,207 @@
+package tools
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+var testCreateTagInput = Tag{
+	Type:    "Used",
+	BGColor: "#a9c9a4",
+}
+
+const testCreateTagAndGetIDOutputJSON = `
+{
+  "code": 201,
+  "success": true,
+  "message": "Tag created",
+  "id": "2",
+  "time": 0.005
+}
+`
+
+var testGetTagByIDOutputExpected = Tag{
+	ID:        2,
+	Type:      "Used",
+	ShowTag:   true,
+	BGColor:   "#a9c9a4",
+	FGColor:   "#ffffff",
+	Compress:  "No",
+	Locked:    "Yes",
+	UpdateTag: true,
+}
+
+const testGetTagByIDOutputJSON = `
+{
+  "code": 200,
+  "success": true,
+  "data": {
+    "id": "2",
+    "type": "Used",
+    "showtag": "1",
+    "bgcolor": "#a9c9a4",
+    "fgcolor": "#ffffff",
+    "compress": "No",
+    "locked": "Yes",
+    "updateTag": "1"
+  }
+}
+`
+
+var testListTagsOutputExpected = []Tag{
+	Tag{
+		ID:        2,
+		Type:      "Used",
+		ShowTag:   true,
+		BGColor:   "#a9c9a4",
+		FGColor:   "#ffffff",
+		Compress:  "No",
+		Locked:    "Yes",
+		UpdateTag: true,
+	},
+}
+
+const testListTagsOutputJSON = `
+{
+  "code": 200,
+  "success": true,
+  "data": [
+    {
+      "id": "2",
+      "type": "Used",
+      "showtag": "1",
+      "bgcolor": "#a9c9a4",
+      "fgcolor": "#ffffff",
+      "compress": "No",
+      "locked": "Yes",
+      "updateTag": "1"
+    }
+  ]
+}
+`
+
+var testUpdateTagInput = Tag{
+	ID:      2,
+	BGColor: "foobat",
+}
+
+const testUpdateTagOutputExpected = `Tag updated`
+const testUpdateTagOutputJSON = `
+{
+  "code": 200,
+  "success": true,
+  "data": "Tag updated"
+}
+`
+
+const testDeleteTagOutputExpected = `Tag deleted`
+const testDeleteTagOutputJSON = `
+{
+  "code": 200,
+  "success": true,
+  "data": "Tag deleted"
+}
+`
+
+func TestListTags(t *testing.T) {
+	ts := httpOKTestServer(testListTagsOutputJSON)
+	defer ts.Close()
+	sess := fullSessionConfig()
+	sess.Config.Endpoint = ts.URL
+	client := NewController(sess)
+
+	expected := testListTagsOutputExpected
+	actual, err := client.ListTags()
+	if err != nil {
+		t.Fatalf("Bad: %s", err)
+	}
+
+	if !reflect.DeepEqual(expected, actual) {
+		t.Fatalf("Expected %#v, got %#v", expected, actual)
+	}
+}
+
+func TestCreateTagAndGet(t *testing.T) {
+	ts := newHTTPTestServer(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Content-Type", "application/json")
+		switch {
+		case r.Method == "POST" && r.URL.Path == "/0123456789abcdefgh/tools/tags/":
+			http.Error(w, testCreateTagAndGetIDOutputJSON, http.StatusCreated)
+		case r.Method == "GET" && r.URL.Path == "/0123456789abcdefgh/tools/tags/2/":
+			http.Error(w, testGetTagByIDOutputJSON, http.StatusOK)
+		default:
+			http.Error(w, "not found", http.StatusNotFound)
+		}
+	})
+	defer ts.Close()
+	sess := fullSessionConfig()
+	sess.Config.Endpoint = ts.URL
+	client := NewController(sess)
+
+	in := testCreateTagInput
+	expected := testGetTagByIDOutputExpected
+	actual, err := client.CreateTagAndGet(in)
+	if err != nil {
+		t.Fatalf("Bad: %s", err)
+	}
+
+	if !reflect.DeepEqual(expected, actual) {
+		t.Fatalf("Expected %#v, got %#v", expected, actual)
+	}
+}
+
+func TestGetTagByID(t *testing.T) {
+	ts := httpOKTestServer(testGetTagByIDOutputJSON)
+	defer ts.Close()
+	sess := fullSessionConfig()
+	sess.Config.Endpoint = ts.URL
+	client := NewController(sess)
+
+	expected := testGetTagByIDOutputExpected
+	actual, err := client.GetTagByID(2)
+	if err != nil {
+		t.Fatalf("Bad: %s", err)
+	}
+
+	if !reflect.DeepEqual(expected, actual) {
+		t.Fatalf("Expected %#v, got %#v", expected, actual)
+	}
+}
+
+func TestUpdateTag(t *testing.T) {
+	ts := httpOKTestServer(testUpdateTagOutputJSON)
+	defer ts.Close()
+	sess := fullSessionConfig()
+	sess.Config.Endpoint = ts.URL
+	client := NewController(sess)
+
+	in := testUpdateTagInput
+	expected := testUpdateTagOutputExpected
+	actual, err := client.UpdateTag(in)
+	if err != nil {
+		t.Fatalf("Bad: %s", err)
+	}
+
+	if !reflect.DeepEqual(expected, actual) {
+		t.Fatalf("Expected %#v, got %#v", expected, actual)
+	}
+}
+
+func TestDeleteTag(t *testing.T) {
+	ts := httpOKTestServer(testDeleteTagOutputJSON)
+	defer ts.Close()
+	sess := fullSessionConfig()
+	sess.Config.Endpoint = ts.URL
+	client := NewController(sess)
+
+	expected := testDeleteTagOutputExpected
+	actual, err := client.DeleteTag(2)
+	if err != nil {
+		t.Fatalf("Bad: %s", err)
+	}
+
+	if !reflect.DeepEqual(expected, actual) {
+		t.Fatalf("Expected %#v, got %#v", expected, actual)
+	}
+}
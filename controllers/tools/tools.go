@@ -0,0 +1,27 @@
+// Package tools provides types and methods for working with the tools
+// controller.
+//
+// The tools controller hosts a number of sub-controllers that back the IDs
+// found on other resources, such as tags (addresses.Address.Tag), locations
+// (subnets.Subnet.Location), nameserver sets (subnets.Subnet.NameserverID),
+// scan agents (subnets.Subnet.ScanAgent) and device types
+// (devices.Device.Type). A single controller serves all of these.
+package tools
+
+import (
+	"github.com/paybyphone/phpipam-sdk-go/phpipam/client"
+	"github.com/paybyphone/phpipam-sdk-go/phpipam/session"
+)
+
+// Controller is the base client for the Tools controller.
+type Controller struct {
+	client.Client
+}
+
+// NewController returns a new instance of the client for the Tools controller.
+func NewController(sess *session.Session) *Controller {
+	c := &Controller{
+		Client: *client.NewClient(sess),
+	}
+	return c
+}
@@ -3,6 +3,7 @@
 package vlans
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/paybyphone/phpipam-sdk-go/phpipam"
@@ -54,13 +55,55 @@ func NewController(sess *session.Session) *Controller {
 
 // CreateVLAN creates a VLAN by sending a POST request.
 func (c *Controller) CreateVLAN(in VLAN) (message string, err error) {
-	err = c.SendRequest("POST", "/vlans/", &in, &message)
+	return c.CreateVLANWithContext(context.Background(), in)
+}
+
+// CreateVLANWithContext is the same as CreateVLAN, but takes a context.Context.
+func (c *Controller) CreateVLANWithContext(ctx context.Context, in VLAN) (message string, err error) {
+	err = c.SendRequestWithContext(ctx, "POST", "/vlans/", &in, &message)
+	return
+}
+
+// CreateVLANAndGetID creates a VLAN by sending a POST request, and
+// returns the ID of the new VLAN.
+func (c *Controller) CreateVLANAndGetID(in VLAN) (id int, err error) {
+	return c.CreateVLANAndGetIDWithContext(context.Background(), in)
+}
+
+// CreateVLANAndGetIDWithContext is the same as CreateVLANAndGetID, but
+// takes a context.Context.
+func (c *Controller) CreateVLANAndGetIDWithContext(ctx context.Context, in VLAN) (id int, err error) {
+	id, err = c.Client.CreateResourceWithContext(ctx, "/vlans/", &in)
+	return
+}
+
+// CreateVLANAndGet creates a VLAN by sending a POST request, and
+// returns the new VLAN as read back from the API.
+func (c *Controller) CreateVLANAndGet(in VLAN) (out VLAN, err error) {
+	return c.CreateVLANAndGetWithContext(context.Background(), in)
+}
+
+// CreateVLANAndGetWithContext is the same as CreateVLANAndGet, but takes a
+// context.Context.
+func (c *Controller) CreateVLANAndGetWithContext(ctx context.Context, in VLAN) (out VLAN, err error) {
+	var id int
+	id, err = c.CreateVLANAndGetIDWithContext(ctx, in)
+	if err != nil {
+		return
+	}
+	out, err = c.GetVLANByIDWithContext(ctx, id)
 	return
 }
 
 // GetVLANByID GETs a VLAN via its ID in the PHPIPAM database.
 func (c *Controller) GetVLANByID(id int) (out VLAN, err error) {
-	err = c.SendRequest("GET", fmt.Sprintf("/vlans/%d/", id), &struct{}{}, &out)
+	return c.GetVLANByIDWithContext(context.Background(), id)
+}
+
+// GetVLANByIDWithContext is the same as GetVLANByID, but takes a
+// context.Context.
+func (c *Controller) GetVLANByIDWithContext(ctx context.Context, id int) (out VLAN, err error) {
+	err = c.SendRequestWithContext(ctx, "GET", fmt.Sprintf("/vlans/%d/", id), &struct{}{}, &out)
 	return
 }
 
@@ -71,27 +114,77 @@ func (c *Controller) GetVLANByID(id int) (out VLAN, err error) {
 // the output from this method is an array of VLANs, so this function returns a
 // slice.
 func (c *Controller) GetVLANsByNumber(id int) (out []VLAN, err error) {
-	err = c.SendRequest("GET", fmt.Sprintf("/vlans/search/%d/", id), &struct{}{}, &out)
+	return c.GetVLANsByNumberWithContext(context.Background(), id)
+}
+
+// GetVLANsByNumberWithContext is the same as GetVLANsByNumber, but takes a
+// context.Context.
+func (c *Controller) GetVLANsByNumberWithContext(ctx context.Context, id int) (out []VLAN, err error) {
+	err = c.SendRequestWithContext(ctx, "GET", fmt.Sprintf("/vlans/search/%d/", id), &struct{}{}, &out)
+	return
+}
+
+// GetVLANByNumberInDomain GETs a VLAN via its VLAN number, scoped to the L2
+// domain with the supplied ID. Use this over GetVLANsByNumber when the same
+// VLAN number is in use in more than one L2 domain.
+//
+// An error is returned if no VLAN with the number exists in the domain.
+func (c *Controller) GetVLANByNumberInDomain(number, domainID int) (out VLAN, err error) {
+	return c.GetVLANByNumberInDomainWithContext(context.Background(), number, domainID)
+}
+
+// GetVLANByNumberInDomainWithContext is the same as GetVLANByNumberInDomain,
+// but takes a context.Context.
+func (c *Controller) GetVLANByNumberInDomainWithContext(ctx context.Context, number, domainID int) (out VLAN, err error) {
+	var vs []VLAN
+	vs, err = c.GetVLANsByNumberWithContext(ctx, number)
+	if err != nil {
+		return
+	}
+	for _, v := range vs {
+		if v.DomainID == domainID {
+			out = v
+			return
+		}
+	}
+	err = fmt.Errorf("VLAN %d not found in L2 domain %d", number, domainID)
 	return
 }
 
 // GetVLANCustomFieldsSchema GETs the custom fields for the vlans controller via
 // client.GetCustomFieldsSchema.
 func (c *Controller) GetVLANCustomFieldsSchema() (out map[string]phpipam.CustomField, err error) {
-	out, err = c.Client.GetCustomFieldsSchema("vlans")
+	return c.GetVLANCustomFieldsSchemaWithContext(context.Background())
+}
+
+// GetVLANCustomFieldsSchemaWithContext is the same as
+// GetVLANCustomFieldsSchema, but takes a context.Context.
+func (c *Controller) GetVLANCustomFieldsSchemaWithContext(ctx context.Context) (out map[string]phpipam.CustomField, err error) {
+	out, err = c.Client.GetCustomFieldsSchemaWithContext(ctx, "vlans")
 	return
 }
 
 // GetVLANCustomFields GETs the custom fields for a subnet via
 // client.GetCustomFields.
 func (c *Controller) GetVLANCustomFields(id int) (out map[string]interface{}, err error) {
-	out, err = c.Client.GetCustomFields(id, "vlans")
+	return c.GetVLANCustomFieldsWithContext(context.Background(), id)
+}
+
+// GetVLANCustomFieldsWithContext is the same as GetVLANCustomFields, but takes
+// a context.Context.
+func (c *Controller) GetVLANCustomFieldsWithContext(ctx context.Context, id int) (out map[string]interface{}, err error) {
+	out, err = c.Client.GetCustomFieldsWithContext(ctx, id, "vlans")
 	return
 }
 
 // UpdateVLAN updates a VLAN by sending a PATCH request.
 func (c *Controller) UpdateVLAN(in VLAN) (message string, err error) {
-	err = c.SendRequest("PATCH", "/vlans/", &in, &message)
+	return c.UpdateVLANWithContext(context.Background(), in)
+}
+
+// UpdateVLANWithContext is the same as UpdateVLAN, but takes a context.Context.
+func (c *Controller) UpdateVLANWithContext(ctx context.Context, in VLAN) (message string, err error) {
+	err = c.SendRequestWithContext(ctx, "PATCH", "/vlans/", &in, &message)
 	return
 }
 
@@ -102,9 +195,15 @@ func (c *Controller) UpdateVLAN(in VLAN) (message string, err error) {
 // require any other data outside of the ID to update the custom fields,
 // updating a VLAN requires a name as well.
 func (c *Controller) UpdateVLANCustomFields(id int, name string, in map[string]interface{}) (message string, err error) {
+	return c.UpdateVLANCustomFieldsWithContext(context.Background(), id, name, in)
+}
+
+// UpdateVLANCustomFieldsWithContext is the same as UpdateVLANCustomFields, but
+// takes a context.Context.
+func (c *Controller) UpdateVLANCustomFieldsWithContext(ctx context.Context, id int, name string, in map[string]interface{}) (message string, err error) {
 	// Verify that we are only updating fields that are custom fields.
 	var schema map[string]phpipam.CustomField
-	schema, err = c.GetVLANCustomFieldsSchema()
+	schema, err = c.GetVLANCustomFieldsSchemaWithContext(ctx)
 	if err != nil {
 		return
 	}
@@ -127,12 +226,17 @@ func (c *Controller) UpdateVLANCustomFields(id int, name string, in map[string]i
 
 	params["id"] = id
 	params["name"] = name
-	err = c.SendRequest("PATCH", "/vlans/", &params, &message)
+	err = c.SendRequestWithContext(ctx, "PATCH", "/vlans/", &params, &message)
 	return
 }
 
 // DeleteVLAN deletes a VLAN by its ID.
 func (c *Controller) DeleteVLAN(id int) (message string, err error) {
-	err = c.SendRequest("DELETE", fmt.Sprintf("/vlans/%d/", id), &struct{}{}, &message)
+	return c.DeleteVLANWithContext(context.Background(), id)
+}
+
+// DeleteVLANWithContext is the same as DeleteVLAN, but takes a context.Context.
+func (c *Controller) DeleteVLANWithContext(ctx context.Context, id int) (message string, err error) {
+	err = c.SendRequestWithContext(ctx, "DELETE", fmt.Sprintf("/vlans/%d/", id), &struct{}{}, &message)
 	return
 }
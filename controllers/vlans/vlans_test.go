@@ -100,6 +100,38 @@ const testGetVLANsByNumberOutputJSON = `
 }
 `
 
+var testGetVLANByNumberInDomainOutputExpected = VLAN{
+	ID:       4,
+	DomainID: 2,
+	Name:     "barlan",
+	Number:   1000,
+}
+
+const testGetVLANByNumberInDomainOutputJSON = `
+{
+  "code": 200,
+  "success": true,
+  "data": [
+    {
+      "id": "3",
+      "domainId": "1",
+      "name": "foolan",
+      "number": "1000",
+      "description": null,
+      "editDate": null
+    },
+    {
+      "id": "4",
+      "domainId": "2",
+      "name": "barlan",
+      "number": "1000",
+      "description": null,
+      "editDate": null
+    }
+  ]
+}
+`
+
 var testGetVLANCustomFieldsSchemaExpected = map[string]phpipam.CustomField{
 	"CustomTestVLANs": phpipam.CustomField{
 		Name:    "CustomTestVLANs",
@@ -163,6 +195,16 @@ const testDeleteVLANOutputJSON = `
 }
 `
 
+const testCreateVLANAndGetIDOutputJSON = `
+{
+  "code": 201,
+  "success": true,
+  "message": "Vlan created",
+  "id": "2",
+  "time": 0.012
+}
+`
+
 func newHTTPTestServer(f func(w http.ResponseWriter, r *http.Request)) *httptest.Server {
 	ts := httptest.NewServer(http.HandlerFunc(f))
 	return ts
@@ -214,6 +256,38 @@ func TestCreateVLAN(t *testing.T) {
 	}
 }
 
+func TestCreateVLANAndGetID(t *testing.T) {
+	ts := httpCreatedTestServer(testCreateVLANAndGetIDOutputJSON)
+	defer ts.Close()
+	sess := fullSessionConfig()
+	sess.Config.Endpoint = ts.URL
+	client := NewController(sess)
+
+	in := testCreateVLANInput
+	expected := 2
+	actual, err := client.CreateVLANAndGetID(in)
+	if err != nil {
+		t.Fatalf("Bad: %s", err)
+	}
+
+	if expected != actual {
+		t.Fatalf("Expected %#v, got %#v", expected, actual)
+	}
+}
+
+func TestCreateVLANAndGetIDMissingID(t *testing.T) {
+	ts := httpCreatedTestServer(testCreateVLANOutputJSON)
+	defer ts.Close()
+	sess := fullSessionConfig()
+	sess.Config.Endpoint = ts.URL
+	client := NewController(sess)
+
+	in := testCreateVLANInput
+	if _, err := client.CreateVLANAndGetID(in); err == nil {
+		t.Fatalf("Expected error, got none")
+	}
+}
+
 func TestGetVLANByID(t *testing.T) {
 	ts := httpOKTestServer(testGetVLANByIDOutputJSON)
 	defer ts.Close()
@@ -250,6 +324,41 @@ func TestGetVLANsByNumber(t *testing.T) {
 	}
 }
 
+func TestGetVLANByNumberInDomain(t *testing.T) {
+	ts := httpOKTestServer(testGetVLANByNumberInDomainOutputJSON)
+	defer ts.Close()
+	sess := fullSessionConfig()
+	sess.Config.Endpoint = ts.URL
+	client := NewController(sess)
+
+	expected := testGetVLANByNumberInDomainOutputExpected
+	actual, err := client.GetVLANByNumberInDomain(1000, 2)
+	if err != nil {
+		t.Fatalf("Bad: %s", err)
+	}
+
+	if !reflect.DeepEqual(expected, actual) {
+		t.Fatalf("Expected %#v, got %#v", expected, actual)
+	}
+}
+
+func TestGetVLANByNumberInDomainNotFound(t *testing.T) {
+	ts := httpOKTestServer(testGetVLANByNumberInDomainOutputJSON)
+	defer ts.Close()
+	sess := fullSessionConfig()
+	sess.Config.Endpoint = ts.URL
+	client := NewController(sess)
+
+	_, err := client.GetVLANByNumberInDomain(1000, 3)
+	if err == nil {
+		t.Fatalf("Expected error, got none")
+	}
+	expected := "VLAN 1000 not found in L2 domain 3"
+	if err.Error() != expected {
+		t.Fatalf("Expected %q, got %q", expected, err.Error())
+	}
+}
+
 func TestGetVLANCustomFieldsSchema(t *testing.T) {
 	ts := httpOKTestServer(testGetVLANCustomFieldsSchemaJSON)
 	defer ts.Close()
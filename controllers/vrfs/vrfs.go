@@ -0,0 +1,194 @@
+// Package vrfs provides types and methods for working with the VRF
+// controller.
+package vrfs
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/paybyphone/phpipam-sdk-go/controllers/subnets"
+	"github.com/paybyphone/phpipam-sdk-go/phpipam"
+	"github.com/paybyphone/phpipam-sdk-go/phpipam/client"
+	"github.com/paybyphone/phpipam-sdk-go/phpipam/session"
+)
+
+// VRF represents a PHPIPAM VRF.
+type VRF struct {
+	// The VRF ID.
+	ID int `json:"id,string,omitempty"`
+
+	// The name of the VRF.
+	Name string `json:"name,omitempty"`
+
+	// The route distinguisher of the VRF (i.e. "65000:100").
+	RouteDistinguisher string `json:"rd,omitempty"`
+
+	// A detailed description of the VRF.
+	Description string `json:"description,omitempty"`
+
+	// A semicolon-separated list of the IDs of the sections that this VRF
+	// belongs to (i.e. "1;2;3").
+	Sections string `json:"sections,omitempty"`
+
+	// The date of the last edit to this resource.
+	EditDate string `json:"editDate,omitempty"`
+
+	// A map[string]interface{} of custom fields to set on the resource. Note
+	// that this functionality requires PHPIPAM 1.3 or higher with the "Nest
+	// custom fields" flag set on the specific API integration. If this is not
+	// enabled, this map will be nil on GETs and POSTs and PATCHes with this
+	// field set will fail. Use the explicit custom field functions instead.
+	CustomFields map[string]interface{} `json:"custom_fields,omitempty"`
+}
+
+// Controller is the base client for the VRF controller.
+type Controller struct {
+	client.Client
+}
+
+// NewController returns a new instance of the client for the VRF controller.
+func NewController(sess *session.Session) *Controller {
+	c := &Controller{
+		Client: *client.NewClient(sess),
+	}
+	return c
+}
+
+// ListVRFs lists all VRFs.
+func (c *Controller) ListVRFs() (out []VRF, err error) {
+	return c.ListVRFsWithContext(context.Background())
+}
+
+// ListVRFsWithContext is the same as ListVRFs, but takes a context.Context.
+func (c *Controller) ListVRFsWithContext(ctx context.Context) (out []VRF, err error) {
+	err = c.SendRequestWithContext(ctx, "GET", "/vrf/", &struct{}{}, &out)
+	return
+}
+
+// CreateVRF creates a VRF by sending a POST request.
+func (c *Controller) CreateVRF(in VRF) (message string, err error) {
+	return c.CreateVRFWithContext(context.Background(), in)
+}
+
+// CreateVRFWithContext is the same as CreateVRF, but takes a context.Context.
+func (c *Controller) CreateVRFWithContext(ctx context.Context, in VRF) (message string, err error) {
+	err = c.SendRequestWithContext(ctx, "POST", "/vrf/", &in, &message)
+	return
+}
+
+// CreateVRFAndGetID creates a VRF by sending a POST request, and returns the
+// ID of the new VRF.
+func (c *Controller) CreateVRFAndGetID(in VRF) (id int, err error) {
+	return c.CreateVRFAndGetIDWithContext(context.Background(), in)
+}
+
+// CreateVRFAndGetIDWithContext is the same as CreateVRFAndGetID, but takes a
+// context.Context.
+func (c *Controller) CreateVRFAndGetIDWithContext(ctx context.Context, in VRF) (id int, err error) {
+	id, err = c.Client.CreateResourceWithContext(ctx, "/vrf/", &in)
+	return
+}
+
+// CreateVRFAndGet creates a VRF by sending a POST request, and returns the
+// new VRF as read back from the API.
+func (c *Controller) CreateVRFAndGet(in VRF) (out VRF, err error) {
+	return c.CreateVRFAndGetWithContext(context.Background(), in)
+}
+
+// CreateVRFAndGetWithContext is the same as CreateVRFAndGet, but takes a
+// context.Context.
+func (c *Controller) CreateVRFAndGetWithContext(ctx context.Context, in VRF) (out VRF, err error) {
+	var id int
+	id, err = c.CreateVRFAndGetIDWithContext(ctx, in)
+	if err != nil {
+		return
+	}
+	out, err = c.GetVRFByIDWithContext(ctx, id)
+	return
+}
+
+// GetVRFByID GETs a VRF via its ID.
+func (c *Controller) GetVRFByID(id int) (out VRF, err error) {
+	return c.GetVRFByIDWithContext(context.Background(), id)
+}
+
+// GetVRFByIDWithContext is the same as GetVRFByID, but takes a
+// context.Context.
+func (c *Controller) GetVRFByIDWithContext(ctx context.Context, id int) (out VRF, err error) {
+	err = c.SendRequestWithContext(ctx, "GET", fmt.Sprintf("/vrf/%d/", id), &struct{}{}, &out)
+	return
+}
+
+// GetSubnetsInVRF GETs the subnets that belong to a VRF, via a supplied VRF
+// ID.
+func (c *Controller) GetSubnetsInVRF(id int) (out []subnets.Subnet, err error) {
+	return c.GetSubnetsInVRFWithContext(context.Background(), id)
+}
+
+// GetSubnetsInVRFWithContext is the same as GetSubnetsInVRF, but takes a
+// context.Context.
+func (c *Controller) GetSubnetsInVRFWithContext(ctx context.Context, id int) (out []subnets.Subnet, err error) {
+	err = c.SendRequestWithContext(ctx, "GET", fmt.Sprintf("/vrf/%d/subnets/", id), &struct{}{}, &out)
+	return
+}
+
+// GetVRFCustomFieldsSchema GETs the custom fields for the VRF controller via
+// client.GetCustomFieldsSchema.
+func (c *Controller) GetVRFCustomFieldsSchema() (out map[string]phpipam.CustomField, err error) {
+	return c.GetVRFCustomFieldsSchemaWithContext(context.Background())
+}
+
+// GetVRFCustomFieldsSchemaWithContext is the same as GetVRFCustomFieldsSchema,
+// but takes a context.Context.
+func (c *Controller) GetVRFCustomFieldsSchemaWithContext(ctx context.Context) (out map[string]phpipam.CustomField, err error) {
+	out, err = c.Client.GetCustomFieldsSchemaWithContext(ctx, "vrf")
+	return
+}
+
+// GetVRFCustomFields GETs the custom fields for a VRF via
+// client.GetCustomFields.
+func (c *Controller) GetVRFCustomFields(id int) (out map[string]interface{}, err error) {
+	return c.GetVRFCustomFieldsWithContext(context.Background(), id)
+}
+
+// GetVRFCustomFieldsWithContext is the same as GetVRFCustomFields, but takes a
+// context.Context.
+func (c *Controller) GetVRFCustomFieldsWithContext(ctx context.Context, id int) (out map[string]interface{}, err error) {
+	out, err = c.Client.GetCustomFieldsWithContext(ctx, id, "vrf")
+	return
+}
+
+// UpdateVRF updates a VRF by sending a PATCH request.
+func (c *Controller) UpdateVRF(in VRF) (message string, err error) {
+	return c.UpdateVRFWithContext(context.Background(), in)
+}
+
+// UpdateVRFWithContext is the same as UpdateVRF, but takes a context.Context.
+func (c *Controller) UpdateVRFWithContext(ctx context.Context, in VRF) (message string, err error) {
+	err = c.SendRequestWithContext(ctx, "PATCH", "/vrf/", &in, &message)
+	return
+}
+
+// UpdateVRFCustomFields PATCHes the VRF's custom fields via
+// client.UpdateCustomFields.
+func (c *Controller) UpdateVRFCustomFields(id int, in map[string]interface{}) (message string, err error) {
+	return c.UpdateVRFCustomFieldsWithContext(context.Background(), id, in)
+}
+
+// UpdateVRFCustomFieldsWithContext is the same as UpdateVRFCustomFields, but
+// takes a context.Context.
+func (c *Controller) UpdateVRFCustomFieldsWithContext(ctx context.Context, id int, in map[string]interface{}) (message string, err error) {
+	message, err = c.Client.UpdateCustomFieldsWithContext(ctx, id, in, "vrf")
+	return
+}
+
+// DeleteVRF deletes a VRF by its ID.
+func (c *Controller) DeleteVRF(id int) (message string, err error) {
+	return c.DeleteVRFWithContext(context.Background(), id)
+}
+
+// DeleteVRFWithContext is the same as DeleteVRF, but takes a context.Context.
+func (c *Controller) DeleteVRFWithContext(ctx context.Context, id int) (message string, err error) {
+	err = c.SendRequestWithContext(ctx, "DELETE", fmt.Sprintf("/vrf/%d/", id), &struct{}{}, &message)
+	return
+}
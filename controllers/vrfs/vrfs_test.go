@@ -0,0 +1,442 @@
+package vrfs
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/paybyphone/phpipam-sdk-go/controllers/subnets"
+	"github.com/paybyphone/phpipam-sdk-go/phpipam"
+	"github.com/paybyphone/phpipam-sdk-go/phpipam/session"
+	"github.com/paybyphone/phpipam-sdk-go/testacc"
+)
+
+var testCreateVRFInput = VRF{
+	Name:               "cust1",
+	RouteDistinguisher: "65000:100",
+	Description:        "Customer 1 VRF",
+}
+
+const testCreateVRFOutputExpected = `VRF created`
+const testCreateVRFOutputJSON = `
+{
+  "code": 201,
+  "success": true,
+  "data": "VRF created"
+}
+`
+
+const testCreateVRFAndGetIDOutputJSON = `
+{
+  "code": 201,
+  "success": true,
+  "message": "Vrf created",
+  "id": "2",
+  "time": 0.009
+}
+`
+
+var testGetVRFByIDOutputExpected = VRF{
+	ID:                 2,
+	Name:               "cust1",
+	RouteDistinguisher: "65000:100",
+	Description:        "Customer 1 VRF",
+	Sections:           "1",
+}
+
+const testGetVRFByIDOutputJSON = `
+{
+  "code": 200,
+  "success": true,
+  "data": {
+    "id": "2",
+    "name": "cust1",
+    "rd": "65000:100",
+    "description": "Customer 1 VRF",
+    "sections": "1",
+    "editDate": null,
+    "links": [
+      {
+        "rel": "self",
+        "href": "/api/test/vrf/2/"
+      }
+    ]
+  }
+}
+`
+
+var testListVRFsOutputExpected = []VRF{
+	VRF{
+		ID:                 2,
+		Name:               "cust1",
+		RouteDistinguisher: "65000:100",
+		Description:        "Customer 1 VRF",
+	},
+	VRF{
+		ID:                 3,
+		Name:               "cust2",
+		RouteDistinguisher: "65000:200",
+		Description:        "Customer 2 VRF",
+	},
+}
+
+const testListVRFsOutputJSON = `
+{
+  "code": 200,
+  "success": true,
+  "data": [
+    {
+      "id": "2",
+      "name": "cust1",
+      "rd": "65000:100",
+      "description": "Customer 1 VRF",
+      "sections": null,
+      "editDate": null
+    },
+    {
+      "id": "3",
+      "name": "cust2",
+      "rd": "65000:200",
+      "description": "Customer 2 VRF",
+      "sections": null,
+      "editDate": null
+    }
+  ]
+}
+`
+
+var testGetSubnetsInVRFOutputExpected = []subnets.Subnet{
+	subnets.Subnet{
+		ID:            3,
+		SubnetAddress: "10.10.1.0",
+		Mask:          24,
+		SectionID:     1,
+		VRFID:         2,
+	},
+}
+
+const testGetSubnetsInVRFOutputJSON = `
+{
+  "code": 200,
+  "success": true,
+  "data": [
+    {
+      "id": "3",
+      "subnet": "10.10.1.0",
+      "mask": "24",
+      "sectionId": "1",
+      "vrfId": "2"
+    }
+  ]
+}
+`
+
+var testGetVRFCustomFieldsSchemaExpected = map[string]phpipam.CustomField{
+	"CustomTestVRFs": phpipam.CustomField{
+		Name:    "CustomTestVRFs",
+		Type:    "varchar(255)",
+		Comment: "Test field for VRF controller",
+		Null:    "YES",
+		Default: "",
+	},
+}
+
+const testGetVRFCustomFieldsSchemaJSON = `
+{
+  "code": 200,
+  "success": true,
+  "data": {
+    "CustomTestVRFs": {
+      "name": "CustomTestVRFs",
+      "type": "varchar(255)",
+      "Comment": "Test field for VRF controller",
+      "Null": "YES",
+      "Default": ""
+    }
+  }
+}
+`
+
+var testUpdateVRFInput = VRF{
+	ID:          2,
+	Description: "foobat",
+}
+
+const testUpdateVRFOutputExpected = `VRF updated`
+const testUpdateVRFOutputJSON = `
+{
+  "code": 200,
+  "success": true,
+  "data": "VRF updated"
+}
+`
+
+const testDeleteVRFOutputExpected = `VRF deleted`
+const testDeleteVRFOutputJSON = `
+{
+  "code": 200,
+  "success": true,
+  "data": "VRF deleted"
+}
+`
+
+func newHTTPTestServer(f func(w http.ResponseWriter, r *http.Request)) *httptest.Server {
+	ts := httptest.NewServer(http.HandlerFunc(f))
+	return ts
+}
+
+func httpOKTestServer(output string) *httptest.Server {
+	return newHTTPTestServer(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Content-Type", "application/json")
+		http.Error(w, output, http.StatusOK)
+	})
+}
+
+func httpCreatedTestServer(output string) *httptest.Server {
+	return newHTTPTestServer(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Content-Type", "application/json")
+		http.Error(w, output, http.StatusCreated)
+	})
+}
+
+func fullSessionConfig() *session.Session {
+	return &session.Session{
+		Config: phpipam.Config{
+			AppID:    "0123456789abcdefgh",
+			Password: "changeit",
+			Username: "nobody",
+		},
+		Token: session.Token{
+			String: "foobarbazboop",
+		},
+	}
+}
+
+func TestListVRFs(t *testing.T) {
+	ts := httpOKTestServer(testListVRFsOutputJSON)
+	defer ts.Close()
+	sess := fullSessionConfig()
+	sess.Config.Endpoint = ts.URL
+	client := NewController(sess)
+
+	expected := testListVRFsOutputExpected
+	actual, err := client.ListVRFs()
+	if err != nil {
+		t.Fatalf("Bad: %s", err)
+	}
+
+	if !reflect.DeepEqual(expected, actual) {
+		t.Fatalf("Expected %#v, got %#v", expected, actual)
+	}
+}
+
+func TestCreateVRF(t *testing.T) {
+	ts := httpCreatedTestServer(testCreateVRFOutputJSON)
+	defer ts.Close()
+	sess := fullSessionConfig()
+	sess.Config.Endpoint = ts.URL
+	client := NewController(sess)
+
+	in := testCreateVRFInput
+	expected := testCreateVRFOutputExpected
+	actual, err := client.CreateVRF(in)
+	if err != nil {
+		t.Fatalf("Bad: %s", err)
+	}
+
+	if !reflect.DeepEqual(expected, actual) {
+		t.Fatalf("Expected %#v, got %#v", expected, actual)
+	}
+}
+
+func TestCreateVRFAndGet(t *testing.T) {
+	ts := newHTTPTestServer(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Content-Type", "application/json")
+		switch {
+		case r.Method == "POST" && r.URL.Path == "/0123456789abcdefgh/vrf/":
+			http.Error(w, testCreateVRFAndGetIDOutputJSON, http.StatusCreated)
+		case r.Method == "GET" && r.URL.Path == "/0123456789abcdefgh/vrf/2/":
+			http.Error(w, testGetVRFByIDOutputJSON, http.StatusOK)
+		default:
+			http.Error(w, "not found", http.StatusNotFound)
+		}
+	})
+	defer ts.Close()
+	sess := fullSessionConfig()
+	sess.Config.Endpoint = ts.URL
+	client := NewController(sess)
+
+	in := testCreateVRFInput
+	expected := testGetVRFByIDOutputExpected
+	actual, err := client.CreateVRFAndGet(in)
+	if err != nil {
+		t.Fatalf("Bad: %s", err)
+	}
+
+	if !reflect.DeepEqual(expected, actual) {
+		t.Fatalf("Expected %#v, got %#v", expected, actual)
+	}
+}
+
+func TestGetVRFByID(t *testing.T) {
+	ts := httpOKTestServer(testGetVRFByIDOutputJSON)
+	defer ts.Close()
+	sess := fullSessionConfig()
+	sess.Config.Endpoint = ts.URL
+	client := NewController(sess)
+
+	expected := testGetVRFByIDOutputExpected
+	actual, err := client.GetVRFByID(2)
+	if err != nil {
+		t.Fatalf("Bad: %s", err)
+	}
+
+	if !reflect.DeepEqual(expected, actual) {
+		t.Fatalf("Expected %#v, got %#v", expected, actual)
+	}
+}
+
+func TestGetSubnetsInVRF(t *testing.T) {
+	ts := httpOKTestServer(testGetSubnetsInVRFOutputJSON)
+	defer ts.Close()
+	sess := fullSessionConfig()
+	sess.Config.Endpoint = ts.URL
+	client := NewController(sess)
+
+	expected := testGetSubnetsInVRFOutputExpected
+	actual, err := client.GetSubnetsInVRF(2)
+	if err != nil {
+		t.Fatalf("Bad: %s", err)
+	}
+
+	if !reflect.DeepEqual(expected, actual) {
+		t.Fatalf("Expected %#v, got %#v", expected, actual)
+	}
+}
+
+func TestGetVRFCustomFieldsSchema(t *testing.T) {
+	ts := httpOKTestServer(testGetVRFCustomFieldsSchemaJSON)
+	defer ts.Close()
+	sess := fullSessionConfig()
+	sess.Config.Endpoint = ts.URL
+	client := NewController(sess)
+
+	expected := testGetVRFCustomFieldsSchemaExpected
+	actual, err := client.GetVRFCustomFieldsSchema()
+	if err != nil {
+		t.Fatalf("Bad: %s", err)
+	}
+
+	if !reflect.DeepEqual(expected, actual) {
+		t.Fatalf("Expected %#v, got %#v", expected, actual)
+	}
+}
+
+func TestUpdateVRF(t *testing.T) {
+	ts := httpOKTestServer(testUpdateVRFOutputJSON)
+	defer ts.Close()
+	sess := fullSessionConfig()
+	sess.Config.Endpoint = ts.URL
+	client := NewController(sess)
+
+	in := testUpdateVRFInput
+	expected := testUpdateVRFOutputExpected
+	actual, err := client.UpdateVRF(in)
+	if err != nil {
+		t.Fatalf("Bad: %s", err)
+	}
+
+	if !reflect.DeepEqual(expected, actual) {
+		t.Fatalf("Expected %#v, got %#v", expected, actual)
+	}
+}
+
+func TestDeleteVRF(t *testing.T) {
+	ts := httpOKTestServer(testDeleteVRFOutputJSON)
+	defer ts.Close()
+	sess := fullSessionConfig()
+	sess.Config.Endpoint = ts.URL
+	client := NewController(sess)
+
+	expected := testDeleteVRFOutputExpected
+	actual, err := client.DeleteVRF(2)
+	if err != nil {
+		t.Fatalf("Bad: %s", err)
+	}
+
+	if !reflect.DeepEqual(expected, actual) {
+		t.Fatalf("Expected %#v, got %#v", expected, actual)
+	}
+}
+
+// testAccVRFCRUDCreate tests the creation part of the VRF controller CRUD
+// acceptance test, and returns the ID of the new VRF.
+func testAccVRFCRUDCreate(t *testing.T, sess *session.Session, v VRF) int {
+	c := NewController(sess)
+
+	id, err := c.CreateVRFAndGetID(v)
+	if err != nil {
+		t.Fatalf("Create: Error creating VRF: %s", err)
+	}
+	return id
+}
+
+// testAccVRFCRUDReadByID tests the read part of the VRF controller
+// acceptance test, by fetching the VRF by ID.
+func testAccVRFCRUDReadByID(t *testing.T, sess *session.Session, v VRF) {
+	c := NewController(sess)
+
+	out, err := c.GetVRFByID(v.ID)
+	if err != nil {
+		t.Fatalf("Can't find VRF by ID: %s", err)
+	}
+
+	// Update fields set by the server in the original
+	v.EditDate = out.EditDate
+
+	if !reflect.DeepEqual(v, out) {
+		t.Fatalf("ReadByID: Expected %#v, got %#v", v, out)
+	}
+}
+
+// testAccVRFCRUDUpdate tests the update part of the VRF controller
+// acceptance test.
+func testAccVRFCRUDUpdate(t *testing.T, sess *session.Session, v VRF) {
+	c := NewController(sess)
+
+	if _, err := c.UpdateVRF(v); err != nil {
+		t.Fatalf("Error updating VRF: %s", err)
+	}
+
+	testAccVRFCRUDReadByID(t, sess, v)
+}
+
+// testAccVRFCRUDDelete tests the delete part of the VRF controller
+// acceptance test.
+func testAccVRFCRUDDelete(t *testing.T, sess *session.Session, v VRF) {
+	c := NewController(sess)
+
+	if _, err := c.DeleteVRF(v.ID); err != nil {
+		t.Fatalf("Error deleting VRF: %s", err)
+	}
+
+	// check to see if VRF is actually gone
+	if _, err := c.GetVRFByID(v.ID); err == nil {
+		t.Fatalf("VRF still present after delete")
+	}
+}
+
+// TestAccVRFCRUD runs a full create-read-update-delete test for a PHPIPAM
+// VRF.
+func TestAccVRFCRUD(t *testing.T) {
+	testacc.VetAccConditions(t)
+
+	sess := session.NewSession()
+	vrf := testCreateVRFInput
+	vrf.ID = testAccVRFCRUDCreate(t, sess, vrf)
+	testAccVRFCRUDReadByID(t, sess, vrf)
+	vrf.Description = "Updating VRF!"
+	testAccVRFCRUDUpdate(t, sess, vrf)
+	testAccVRFCRUDDelete(t, sess, vrf)
+}
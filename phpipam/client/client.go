@@ -3,7 +3,9 @@
 package client
 
 import (
+	"context"
 	"fmt"
+	"time"
 
 	"github.com/paybyphone/phpipam-sdk-go/phpipam"
 	"github.com/paybyphone/phpipam-sdk-go/phpipam/request"
@@ -28,17 +30,35 @@ func NewClient(s *session.Session) *Client {
 // loginSession logs in a session via the user controller. This is the only
 // valid operation if the session does not have a token yet.
 func loginSession(s *session.Session) error {
-	var out session.Token
-	r := request.NewRequest(s)
-	r.Method = "POST"
-	r.URI = "/user/"
-	r.Input = &struct{}{}
-	r.Output = &out
-	if err := r.Send(); err != nil {
-		return err
-	}
-	s.Token = out
-	return nil
+	return loginSessionWithContext(context.Background(), s)
+}
+
+// loginSessionWithContext is the same as loginSession, but takes a
+// context.Context that is honoured during the login request.
+//
+// Concurrent logins for the same session are collapsed into a single request.
+func loginSessionWithContext(ctx context.Context, s *session.Session) error {
+	return refreshSession(ctx, s, s.CurrentToken())
+}
+
+// refreshSession logs in a session to replace the token in stale, unless
+// another caller has already done so. See session.Session.Refresh for more
+// details.
+func refreshSession(ctx context.Context, s *session.Session, stale session.Token) error {
+	return s.Refresh(ctx, stale, func(ctx context.Context) (session.Token, error) {
+		var out session.Token
+		r := request.NewRequest(s)
+		r.Method = "POST"
+		r.URI = "/user/"
+		r.Input = &struct{}{}
+		r.Output = &out
+		r.BasicAuth = true
+		if err := r.SendWithContext(ctx); err != nil {
+			return out, err
+		}
+		out.Deadline = s.TokenDeadline(out.Expires, r.ResponseHeader.Get("Date"), time.Now())
+		return out, nil
+	})
 }
 
 // SendRequest sends a request to a request.Request object.  It's expected that
@@ -46,40 +66,226 @@ func loginSession(s *session.Session) error {
 // sure that references are passed.
 //
 // This function also wraps session management into the workflow, logging in
-// and refreshing session tokens as needed.
+// and refreshing session tokens as needed. It is safe to share a session
+// between many clients that send requests concurrently.
 func (c *Client) SendRequest(method, uri string, in, out interface{}) error {
-	// Check to make sure our session is ok first.
-	if c.Session.Token.String == "" {
-		if err := loginSession(c.Session); err != nil {
-			return fmt.Errorf("Error logging into PHPIPAM: %s", err)
-		}
-	}
+	return c.SendRequestWithContext(context.Background(), method, uri, in, out)
+}
+
+// SendRequestWithContext is the same as SendRequest, but takes a
+// context.Context. The context is honoured during any login or token refresh
+// that needs to happen, in addition to the request itself.
+//
+// Requests are subject to the session's rate limit and in-flight request cap,
+// if configured. The limits apply to every HTTP request that is sent, including
+// logins, token refreshes, and retries.
+func (c *Client) SendRequestWithContext(ctx context.Context, method, uri string, in, out interface{}) error {
+	_, err := c.SendRequestEnvelopeWithContext(ctx, method, uri, in, out)
+	return err
+}
+
+// SendRequestEnvelope works like SendRequest, but also returns the full
+// response envelope, which includes details such as the ID of a resource
+// created by a POST request.
+func (c *Client) SendRequestEnvelope(method, uri string, in, out interface{}) (request.APIResponse, error) {
+	return c.SendRequestEnvelopeWithContext(context.Background(), method, uri, in, out)
+}
 
+// SendRequestEnvelopeWithContext is the same as SendRequestEnvelope, but
+// takes a context.Context.
+func (c *Client) SendRequestEnvelopeWithContext(ctx context.Context, method, uri string, in, out interface{}) (resp request.APIResponse, err error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
 	r := request.NewRequest(c.Session)
 	r.Method = method
 	r.URI = uri
 	r.Input = in
 	r.Output = out
-	err := r.Send()
+	if err = c.send(ctx, r); err != nil {
+		return
+	}
+	if r.Response != nil {
+		resp = *r.Response
+	}
+	return
+}
+
+// send sends r, logging in and refreshing the session token as needed.
+func (c *Client) send(ctx context.Context, r *request.Request) error {
+	// Static app code tokens and encrypted requests don't need a session.
+	if c.Session.Config.AuthMode == phpipam.AuthModeAppCode || c.Session.Config.AuthMode == phpipam.AuthModeCrypt {
+		return r.SendWithContext(ctx)
+	}
+
+	// Check to make sure our session is ok first.
+	token := c.Session.CurrentToken()
+	if token.String == "" {
+		if err := refreshSession(ctx, c.Session, token); err != nil {
+			return fmt.Errorf("Error logging into PHPIPAM: %w", err)
+		}
+		token = c.Session.CurrentToken()
+	}
+
+	// Refresh the token ahead of time if it's about to expire. If this fails,
+	// the current token is still used, as it may not have lapsed yet.
+	if c.Session.TokenExpiring() {
+		if err := refreshSession(ctx, c.Session, token); err != nil && ctx.Err() != nil {
+			return ctx.Err()
+		}
+		token = c.Session.CurrentToken()
+	}
+
+	err := r.SendWithContext(ctx)
 	switch {
 	case err == nil:
 		return nil
-	case err.Error() == "Error from API (403): Token expired":
-		if err := loginSession(c.Session); err != nil {
-			return fmt.Errorf("Error refreshing expired PHPIPAM session token: %s", err)
+	case phpipam.IsTokenExpired(err):
+		if err := refreshSession(ctx, c.Session, token); err != nil {
+			return fmt.Errorf("Error refreshing expired PHPIPAM session token: %w", err)
 		}
-		return r.Send()
+		return r.SendWithContext(ctx)
 	}
 	return err
 }
 
+// CreateResource POSTs in to uri, and returns the ID of the created resource
+// from the response envelope. An error is returned if the API did not return
+// an ID.
+//
+// This function is called out to in a controller to implement this
+// functionality in a specific pacakge.
+func (c *Client) CreateResource(uri string, in interface{}) (id int, err error) {
+	return c.CreateResourceWithContext(context.Background(), uri, in)
+}
+
+// CreateResourceWithContext is the same as CreateResource, but takes a
+// context.Context.
+func (c *Client) CreateResourceWithContext(ctx context.Context, uri string, in interface{}) (id int, err error) {
+	var data interface{}
+	var resp request.APIResponse
+	resp, err = c.SendRequestEnvelopeWithContext(ctx, "POST", uri, in, &data)
+	if err != nil {
+		return
+	}
+	if resp.ID == 0 {
+		err = fmt.Errorf("API did not return the ID of the resource created at %s", uri)
+		return
+	}
+	id = resp.ID
+	return
+}
+
+// sendUserRequest sends a request to the user controller with the session's
+// current token, without any of the login or refresh logic in SendRequest.
+// The token that the request was sent with is returned, along with the
+// request, which holds the response headers on success.
+func (c *Client) sendUserRequest(ctx context.Context, method string, out interface{}) (session.Token, *request.Request, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	token := c.Session.CurrentToken()
+	if token.String == "" {
+		return token, nil, fmt.Errorf("Session is not logged in")
+	}
+
+	r := request.NewRequest(c.Session)
+	r.Method = method
+	r.URI = "/user/"
+	r.Input = &struct{}{}
+	r.Output = out
+	return token, r, r.SendWithContext(ctx)
+}
+
+// TokenStatus GETs the status of the session's current token from the user
+// controller, and returns the token with its expiry time as reported by the
+// API. An error is returned if the session is not logged in, or if the token
+// is no longer valid - use phpipam.IsTokenExpired to check for the latter.
+//
+// Unlike other requests, this does not log in or refresh the token.
+func (c *Client) TokenStatus() (out session.Token, err error) {
+	return c.TokenStatusWithContext(context.Background())
+}
+
+// TokenStatusWithContext is the same as TokenStatus, but takes a
+// context.Context.
+func (c *Client) TokenStatusWithContext(ctx context.Context) (out session.Token, err error) {
+	var status session.Token
+	var r *request.Request
+	out, r, err = c.sendUserRequest(ctx, "GET", &status)
+	if err != nil {
+		return
+	}
+	out.Expires = status.Expires
+	out.Deadline = c.Session.TokenDeadline(status.Expires, r.ResponseHeader.Get("Date"), time.Now())
+	return
+}
+
+// ExtendToken PATCHes the user controller to extend the validity of the
+// session's current token, without sending the username and password again.
+// The session's token is updated with the new expiry time, and the token is
+// returned.
+//
+// An error is returned if the session is not logged in, or if the token is no
+// longer valid.
+func (c *Client) ExtendToken() (out session.Token, err error) {
+	return c.ExtendTokenWithContext(context.Background())
+}
+
+// ExtendTokenWithContext is the same as ExtendToken, but takes a
+// context.Context.
+func (c *Client) ExtendTokenWithContext(ctx context.Context) (out session.Token, err error) {
+	var status session.Token
+	var old session.Token
+	var r *request.Request
+	old, r, err = c.sendUserRequest(ctx, "PATCH", &status)
+	if err != nil {
+		return
+	}
+	out = old
+	out.Expires = status.Expires
+	out.Deadline = c.Session.TokenDeadline(status.Expires, r.ResponseHeader.Get("Date"), time.Now())
+	c.Session.CompareAndSetToken(old, out)
+	return
+}
+
+// Logout DELETEs the session's current token via the user controller, and
+// clears the token from the session. Logging out of a session that is not
+// logged in, or whose token has already expired, is not an error.
+func (c *Client) Logout() error {
+	return c.LogoutWithContext(context.Background())
+}
+
+// LogoutWithContext is the same as Logout, but takes a context.Context.
+func (c *Client) LogoutWithContext(ctx context.Context) error {
+	if c.Session.CurrentToken().String == "" {
+		return nil
+	}
+	token, _, err := c.sendUserRequest(ctx, "DELETE", &struct{}{})
+	if token.String == "" {
+		// Logged out from under us.
+		return nil
+	}
+	if err != nil && !phpipam.IsTokenExpired(err) && !phpipam.IsAuthFailure(err) {
+		return err
+	}
+	c.Session.CompareAndSetToken(token, session.Token{})
+	return nil
+}
+
 // GetCustomFieldsSchema GETs the custom fields for the supplied controller
 // name and returns them as a map[string]phpipam.CustomField.
 //
 // This function is called out to in a controller to implement this
 // functionality in a specific pacakge.
 func (c *Client) GetCustomFieldsSchema(controller string) (out map[string]phpipam.CustomField, err error) {
-	err = c.SendRequest("GET", fmt.Sprintf("/%s/custom_fields/", controller), &struct{}{}, &out)
+	return c.GetCustomFieldsSchemaWithContext(context.Background(), controller)
+}
+
+// GetCustomFieldsSchemaWithContext is the same as GetCustomFieldsSchema, but
+// takes a context.Context.
+func (c *Client) GetCustomFieldsSchemaWithContext(ctx context.Context, controller string) (out map[string]phpipam.CustomField, err error) {
+	err = c.SendRequestWithContext(ctx, "GET", fmt.Sprintf("/%s/custom_fields/", controller), &struct{}{}, &out)
 	return
 }
 
@@ -97,20 +303,26 @@ func (c *Client) GetCustomFieldsSchema(controller string) (out map[string]phpipa
 // This function is called out to in a controller to implement this
 // functionality in a specific pacakge.
 func (c *Client) GetCustomFields(id int, controller string) (out map[string]interface{}, err error) {
+	return c.GetCustomFieldsWithContext(context.Background(), id, controller)
+}
+
+// GetCustomFieldsWithContext is the same as GetCustomFields, but takes a
+// context.Context.
+func (c *Client) GetCustomFieldsWithContext(ctx context.Context, id int, controller string) (out map[string]interface{}, err error) {
 	var schema map[string]phpipam.CustomField
-	schema, err = c.GetCustomFieldsSchema(controller)
+	schema, err = c.GetCustomFieldsSchemaWithContext(ctx, controller)
 	if err != nil {
 		return
 	}
 
-	out, err = c.getCustomFieldsRequest(id, controller, schema)
+	out, err = c.getCustomFieldsRequest(ctx, id, controller, schema)
 	return
 }
 
 // getCustomFieldsRequest performs the actual work for GetCustomFields. This is
 // separated off to make testing easier.
-func (c *Client) getCustomFieldsRequest(id int, controller string, schema map[string]phpipam.CustomField) (out map[string]interface{}, err error) {
-	err = c.SendRequest("GET", fmt.Sprintf("/%s/%d/", controller, id), &struct{}{}, &out)
+func (c *Client) getCustomFieldsRequest(ctx context.Context, id int, controller string, schema map[string]phpipam.CustomField) (out map[string]interface{}, err error) {
+	err = c.SendRequestWithContext(ctx, "GET", fmt.Sprintf("/%s/%d/", controller, id), &struct{}{}, &out)
 	if err != nil {
 		return
 	}
@@ -141,18 +353,24 @@ func (c *Client) getCustomFieldsRequest(id int, controller string, schema map[st
 // This function is called out to in a controller to implement this
 // functionality in a specific pacakge.
 func (c *Client) UpdateCustomFields(id int, in map[string]interface{}, controller string) (message string, err error) {
+	return c.UpdateCustomFieldsWithContext(context.Background(), id, in, controller)
+}
+
+// UpdateCustomFieldsWithContext is the same as UpdateCustomFields, but takes a
+// context.Context.
+func (c *Client) UpdateCustomFieldsWithContext(ctx context.Context, id int, in map[string]interface{}, controller string) (message string, err error) {
 	var schema map[string]phpipam.CustomField
-	schema, err = c.GetCustomFieldsSchema(controller)
+	schema, err = c.GetCustomFieldsSchemaWithContext(ctx, controller)
 	if err != nil {
 		return
 	}
-	message, err = c.updateCustomFieldsRequest(id, in, controller, schema)
+	message, err = c.updateCustomFieldsRequest(ctx, id, in, controller, schema)
 	return
 }
 
 // updateCustomFieldsRequest performs the actual validation and request work
 // for UpdateCustomFields. This is separated off to make testing easier.
-func (c *Client) updateCustomFieldsRequest(id int, in map[string]interface{}, controller string, schema map[string]phpipam.CustomField) (message string, err error) {
+func (c *Client) updateCustomFieldsRequest(ctx context.Context, id int, in map[string]interface{}, controller string, schema map[string]phpipam.CustomField) (message string, err error) {
 	for k := range in {
 		for l := range schema {
 			if k == l {
@@ -171,6 +389,6 @@ func (c *Client) updateCustomFieldsRequest(id int, in map[string]interface{}, co
 	}
 
 	params["id"] = id
-	err = c.SendRequest("PATCH", fmt.Sprintf("/%s/", controller), &params, &message)
+	err = c.SendRequestWithContext(ctx, "PATCH", fmt.Sprintf("/%s/", controller), &params, &message)
 	return
 }
@@ -1,12 +1,17 @@
 package client
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"reflect"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/paybyphone/phpipam-sdk-go/phpipam"
 	"github.com/paybyphone/phpipam-sdk-go/phpipam/session"
@@ -152,6 +157,32 @@ type testSubnetDataResponse struct {
 	Data []testSubnetData
 }
 
+const tokenExpiredResponseText = `
+{
+  "code": 403,
+  "success": false,
+  "message": "Token expired"
+}
+`
+
+const tokenStatusResponseText = `
+{
+  "code": 200,
+  "success": true,
+  "data": {
+    "expires": "2999-12-31 23:59:59"
+  }
+}
+`
+
+const logoutResponseText = `
+{
+  "code": 200,
+  "success": true,
+  "message": "User deauthenticated"
+}
+`
+
 const subnetSearchErrorResponseText = `
 {
   "code": 404,
@@ -266,6 +297,26 @@ func httpUpdateCustomFieldsRequestTestServer() *httptest.Server {
 	})
 }
 
+// httpTokenExpiredTestServer returns a server that rejects the first
+// non-login request with a token expired error. Subsequent requests succeed
+// only if they come with the token from a fresh login.
+func httpTokenExpiredTestServer(logins *int) *httptest.Server {
+	expired := false
+	return newHTTPTestServer(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Content-Type", "application/json")
+		switch {
+		case r.URL.Path == "/0123456789abcdefgh/user/":
+			*logins++
+			http.Error(w, authOKResponseText, http.StatusOK)
+		case !expired:
+			expired = true
+			http.Error(w, tokenExpiredResponseText, http.StatusForbidden)
+		default:
+			http.Error(w, subnetSearchOKResponseText, http.StatusOK)
+		}
+	})
+}
+
 func phpipamConfig() phpipam.Config {
 	return phpipam.Config{
 		AppID:    "0123456789abcdefgh",
@@ -283,6 +334,16 @@ func fullSessionConfig() *session.Session {
 	}
 }
 
+// withoutDeadline checks that the deadline of a token received from the API
+// was set, and returns the token with it cleared, for comparison.
+func withoutDeadline(t *testing.T, token session.Token) session.Token {
+	if token.Deadline.IsZero() {
+		t.Fatalf("Expected token deadline to be set, got %#v", token)
+	}
+	token.Deadline = time.Time{}
+	return token
+}
+
 func TestNewClient(t *testing.T) {
 	sess := session.NewSession(phpipamConfig())
 
@@ -309,9 +370,10 @@ func TestLoginSessionSuccess(t *testing.T) {
 	}
 
 	expected := session.Token{
-		String: "foobarbazboop",
+		String:  "foobarbazboop",
+		Expires: testDateStamp,
 	}
-	actual := client.Session.Token
+	actual := withoutDeadline(t, client.Session.Token)
 
 	if !reflect.DeepEqual(expected, actual) {
 		t.Fatalf("Expected session token to be %#v, got %#v", expected, actual)
@@ -339,6 +401,27 @@ func TestLoginSessionError(t *testing.T) {
 	}
 }
 
+func TestLoginSessionWithContextCancelled(t *testing.T) {
+	ts := httpAuthOKTestServer()
+	defer ts.Close()
+	cfg := phpipamConfig()
+	cfg.Endpoint = ts.URL
+	sess := session.NewSession(cfg)
+	client := NewClient(sess)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := loginSessionWithContext(ctx, client.Session)
+
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected error to be %s, got %v", context.Canceled, err)
+	}
+
+	if client.Session.Token.String != "" {
+		t.Fatalf("Expected session token to be empty, got %q", client.Session.Token.String)
+	}
+}
+
 func TestSendRequestSuccess(t *testing.T) {
 	ts := httpSubnetSearchOKTestServer()
 	defer ts.Close()
@@ -384,6 +467,410 @@ func TestSendRequestError(t *testing.T) {
 	}
 }
 
+func TestSendRequestTokenExpired(t *testing.T) {
+	var logins int
+	ts := httpTokenExpiredTestServer(&logins)
+	defer ts.Close()
+	sess := fullSessionConfig()
+	sess.Config.Endpoint = ts.URL
+	sess.Token.String = "expiredtoken"
+	client := NewClient(sess)
+
+	actual := make([]testSubnetData, 0)
+	if err := client.SendRequest("GET", "/subnets/cidr/10.10.1.0/24/", struct{}{}, &actual); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	if logins != 1 {
+		t.Fatalf("Expected 1 login, got %d", logins)
+	}
+
+	if client.Session.Token.String != "foobarbazboop" {
+		t.Fatalf("Expected session token to be refreshed, got %q", client.Session.Token.String)
+	}
+}
+
+func TestSendRequestTokenExpiring(t *testing.T) {
+	var logins int32
+	ts := newHTTPTestServer(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Content-Type", "application/json")
+		if r.URL.Path == "/0123456789abcdefgh/user/" {
+			atomic.AddInt32(&logins, 1)
+			http.Error(w, authOKResponseText, http.StatusOK)
+			return
+		}
+		http.Error(w, subnetSearchOKResponseText, http.StatusOK)
+	})
+	defer ts.Close()
+	sess := fullSessionConfig()
+	sess.Config.Endpoint = ts.URL
+	sess.Token.Deadline = time.Now().Add(30 * time.Second)
+	client := NewClient(sess)
+
+	actual := make([]testSubnetData, 0)
+	if err := client.SendRequest("GET", "/subnets/cidr/10.10.1.0/24/", struct{}{}, &actual); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	if logins != 1 {
+		t.Fatalf("Expected 1 login, got %d", logins)
+	}
+
+	if client.Session.Token.Expires != testDateStamp {
+		t.Fatalf("Expected token expiry to be %s, got %s", testDateStamp, client.Session.Token.Expires)
+	}
+
+	// The refreshed token is good for a long time, so another request should
+	// not trigger a login.
+	if err := client.SendRequest("GET", "/subnets/cidr/10.10.1.0/24/", struct{}{}, &actual); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	if logins != 1 {
+		t.Fatalf("Expected 1 login, got %d", logins)
+	}
+}
+
+func TestSendRequestTokenLocalTimeZone(t *testing.T) {
+	// Run ahead of the server, which is in UTC. Expiry times read in the local
+	// time zone would have every token look like it has already expired.
+	defer func(l *time.Location) { time.Local = l }(time.Local)
+	time.Local = time.FixedZone("UTC+14", 14*60*60)
+
+	var logins int32
+	ts := newHTTPTestServer(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Content-Type", "application/json")
+		if r.URL.Path == "/0123456789abcdefgh/user/" {
+			atomic.AddInt32(&logins, 1)
+			expires := time.Now().UTC().Add(6 * time.Hour).Format("2006-01-02 15:04:05")
+			http.Error(w, fmt.Sprintf(`{"code":200,"success":true,"data":{"token":"foobarbazboop","expires":"%s"}}`, expires), http.StatusOK)
+			return
+		}
+		http.Error(w, subnetSearchOKResponseText, http.StatusOK)
+	})
+	defer ts.Close()
+	cfg := phpipamConfig()
+	cfg.Endpoint = ts.URL
+	sess := session.NewSession(cfg)
+	client := NewClient(sess)
+
+	for i := 0; i < 5; i++ {
+		actual := make([]testSubnetData, 0)
+		if err := client.SendRequest("GET", "/subnets/cidr/10.10.1.0/24/", struct{}{}, &actual); err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+	}
+
+	if logins != 1 {
+		t.Fatalf("Expected 1 login, got %d", logins)
+	}
+
+	if d := time.Until(sess.ExpiresAt()); d < 5*time.Hour || d > 6*time.Hour {
+		t.Fatalf("Expected token to expire in about 6h, got %s", d)
+	}
+}
+
+func TestSendRequestErrorIsNotFound(t *testing.T) {
+	ts := httpSubnetSearchErrorTestServer()
+	defer ts.Close()
+	sess := fullSessionConfig()
+	sess.Config.Endpoint = ts.URL
+	client := NewClient(sess)
+
+	tmp := make([]testSubnetData, 0)
+	err := client.SendRequest("GET", "/subnets/cidr/10.10.1.0/24/", struct{}{}, &tmp)
+
+	if !phpipam.IsNotFound(err) {
+		t.Fatalf("Expected a not found error, got %#v", err)
+	}
+}
+
+func TestSendRequestMaxInFlight(t *testing.T) {
+	var current, max int32
+	ts := newHTTPTestServer(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&current, 1)
+		defer atomic.AddInt32(&current, -1)
+		for {
+			m := atomic.LoadInt32(&max)
+			if n <= m || atomic.CompareAndSwapInt32(&max, m, n) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+		w.Header().Add("Content-Type", "application/json")
+		http.Error(w, subnetSearchOKResponseText, http.StatusOK)
+	})
+	defer ts.Close()
+	sess := fullSessionConfig()
+	sess.Config.Endpoint = ts.URL
+	sess.Config.MaxInFlight = 2
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			client := NewClient(sess)
+			out := make([]testSubnetData, 0)
+			if err := client.SendRequest("GET", "/subnets/cidr/10.10.1.0/24/", struct{}{}, &out); err != nil {
+				t.Errorf("Unexpected error: %s", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if max > 2 {
+		t.Fatalf("Expected at most 2 requests in flight, got %d", max)
+	}
+}
+
+func TestSendRequestRateLimitRetries(t *testing.T) {
+	var logins, calls int32
+	ts := newHTTPTestServer(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Content-Type", "application/json")
+		if r.URL.Path == "/0123456789abcdefgh/user/" {
+			atomic.AddInt32(&logins, 1)
+			http.Error(w, authOKResponseText, http.StatusOK)
+			return
+		}
+		atomic.AddInt32(&calls, 1)
+		http.Error(w, "Service Unavailable", http.StatusServiceUnavailable)
+	})
+	defer ts.Close()
+	cfg := phpipamConfig()
+	cfg.Endpoint = ts.URL
+	cfg.RateLimit = 20
+	cfg.MaxInFlight = 1
+	cfg.Retry = phpipam.RetryPolicy{
+		MaxAttempts: 3,
+		MinBackoff:  time.Millisecond,
+		MaxBackoff:  time.Millisecond,
+	}
+	sess := session.NewSession(cfg)
+	client := NewClient(sess)
+
+	start := time.Now()
+	out := make([]testSubnetData, 0)
+	if err := client.SendRequest("GET", "/subnets/cidr/10.10.1.0/24/", struct{}{}, &out); err == nil {
+		t.Fatalf("Expected error, got none")
+	}
+	elapsed := time.Since(start)
+
+	if logins != 1 {
+		t.Fatalf("Expected 1 login, got %d", logins)
+	}
+	if calls != 3 {
+		t.Fatalf("Expected 3 calls, got %d", calls)
+	}
+	// The login and each of the 3 attempts take a token, with a burst of 1 at
+	// 20 requests per second.
+	if min := 150 * time.Millisecond; elapsed < min {
+		t.Fatalf("Expected all requests to be rate limited, taking at least %s, took %s", min, elapsed)
+	}
+}
+
+// httpUserTestServer returns a server that serves output for requests to the
+// user controller with the supplied method, checking that the session token
+// was sent. The number of requests received is recorded in calls.
+func httpUserTestServer(t *testing.T, method, output string, calls *int32) *httptest.Server {
+	return newHTTPTestServer(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(calls, 1)
+		if r.Method != method || r.URL.Path != "/0123456789abcdefgh/user/" {
+			t.Errorf("Unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+		if r.Header.Get("phpipam-token") != "foobarbazboop" {
+			t.Errorf("Expected token foobarbazboop, got %q", r.Header.Get("phpipam-token"))
+		}
+		w.Header().Add("Content-Type", "application/json")
+		http.Error(w, output, http.StatusOK)
+	})
+}
+
+func TestTokenStatus(t *testing.T) {
+	var calls int32
+	ts := httpUserTestServer(t, "GET", tokenStatusResponseText, &calls)
+	defer ts.Close()
+	sess := fullSessionConfig()
+	sess.Config.Endpoint = ts.URL
+	client := NewClient(sess)
+
+	expected := session.Token{
+		String:  "foobarbazboop",
+		Expires: testDateStamp,
+	}
+	actual, err := client.TokenStatus()
+	if err != nil {
+		t.Fatalf("Bad: %s", err)
+	}
+	actual = withoutDeadline(t, actual)
+
+	if !reflect.DeepEqual(expected, actual) {
+		t.Fatalf("Expected %#v, got %#v", expected, actual)
+	}
+}
+
+func TestTokenStatusNotLoggedIn(t *testing.T) {
+	var calls int32
+	ts := httpUserTestServer(t, "GET", tokenStatusResponseText, &calls)
+	defer ts.Close()
+	sess := fullSessionConfig()
+	sess.Config.Endpoint = ts.URL
+	sess.Token = session.Token{}
+	client := NewClient(sess)
+
+	if _, err := client.TokenStatus(); err == nil {
+		t.Fatalf("Expected error, got none")
+	}
+
+	if calls != 0 {
+		t.Fatalf("Expected no requests, got %d", calls)
+	}
+}
+
+func TestExtendToken(t *testing.T) {
+	var calls int32
+	ts := httpUserTestServer(t, "PATCH", tokenStatusResponseText, &calls)
+	defer ts.Close()
+	sess := fullSessionConfig()
+	sess.Config.Endpoint = ts.URL
+	sess.Token.Expires = "2017-03-03 00:56:34"
+	client := NewClient(sess)
+
+	expected := session.Token{
+		String:  "foobarbazboop",
+		Expires: testDateStamp,
+	}
+	actual, err := client.ExtendToken()
+	if err != nil {
+		t.Fatalf("Bad: %s", err)
+	}
+
+	if !reflect.DeepEqual(actual, sess.CurrentToken()) {
+		t.Fatalf("Expected session token to be %#v, got %#v", actual, sess.CurrentToken())
+	}
+
+	actual = withoutDeadline(t, actual)
+	if !reflect.DeepEqual(expected, actual) {
+		t.Fatalf("Expected %#v, got %#v", expected, actual)
+	}
+}
+
+func TestLogout(t *testing.T) {
+	var calls int32
+	ts := httpUserTestServer(t, "DELETE", logoutResponseText, &calls)
+	defer ts.Close()
+	sess := fullSessionConfig()
+	sess.Config.Endpoint = ts.URL
+	client := NewClient(sess)
+
+	if err := client.Logout(); err != nil {
+		t.Fatalf("Bad: %s", err)
+	}
+
+	if calls != 1 {
+		t.Fatalf("Expected 1 request, got %d", calls)
+	}
+
+	if actual := sess.CurrentToken(); actual != (session.Token{}) {
+		t.Fatalf("Expected session token to be cleared, got %#v", actual)
+	}
+
+	// Logging out again is a no-op.
+	if err := client.Logout(); err != nil {
+		t.Fatalf("Bad: %s", err)
+	}
+
+	if calls != 1 {
+		t.Fatalf("Expected 1 request, got %d", calls)
+	}
+}
+
+func TestLogoutTokenExpired(t *testing.T) {
+	ts := newHTTPTestServer(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Content-Type", "application/json")
+		http.Error(w, tokenExpiredResponseText, http.StatusForbidden)
+	})
+	defer ts.Close()
+	sess := fullSessionConfig()
+	sess.Config.Endpoint = ts.URL
+	client := NewClient(sess)
+
+	if err := client.Logout(); err != nil {
+		t.Fatalf("Bad: %s", err)
+	}
+
+	if actual := sess.CurrentToken(); actual != (session.Token{}) {
+		t.Fatalf("Expected session token to be cleared, got %#v", actual)
+	}
+}
+
+// httpAppCodeTestServer returns a server that checks for the app code token
+// and fails any requests to the user controller. If expired is true, all
+// other requests fail with a token expired error.
+func httpAppCodeTestServer(t *testing.T, expired bool, calls *int32) *httptest.Server {
+	return newHTTPTestServer(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(calls, 1)
+		if r.URL.Path == "/0123456789abcdefgh/user/" {
+			t.Errorf("Unexpected login request in app code mode")
+		}
+		if r.Header.Get("token") != "appcode" {
+			t.Errorf("Expected token header to be appcode, got %q", r.Header.Get("token"))
+		}
+		if _, _, ok := r.BasicAuth(); ok {
+			t.Errorf("Unexpected basic auth in app code mode")
+		}
+		w.Header().Add("Content-Type", "application/json")
+		if expired {
+			http.Error(w, tokenExpiredResponseText, http.StatusForbidden)
+			return
+		}
+		http.Error(w, subnetSearchOKResponseText, http.StatusOK)
+	})
+}
+
+func TestSendRequestAppCode(t *testing.T) {
+	var calls int32
+	ts := httpAppCodeTestServer(t, false, &calls)
+	defer ts.Close()
+	cfg := phpipamConfig()
+	cfg.Endpoint = ts.URL
+	cfg.AuthMode = phpipam.AuthModeAppCode
+	cfg.AppCode = "appcode"
+	sess := session.NewSession(cfg)
+	client := NewClient(sess)
+
+	actual := make([]testSubnetData, 0)
+	if err := client.SendRequest("GET", "/subnets/cidr/10.10.1.0/24/", struct{}{}, &actual); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	if calls != 1 {
+		t.Fatalf("Expected 1 request, got %d", calls)
+	}
+}
+
+func TestSendRequestAppCodeNoRefresh(t *testing.T) {
+	var calls int32
+	ts := httpAppCodeTestServer(t, true, &calls)
+	defer ts.Close()
+	cfg := phpipamConfig()
+	cfg.Endpoint = ts.URL
+	cfg.AuthMode = phpipam.AuthModeAppCode
+	cfg.AppCode = "appcode"
+	sess := session.NewSession(cfg)
+	client := NewClient(sess)
+
+	actual := make([]testSubnetData, 0)
+	err := client.SendRequest("GET", "/subnets/cidr/10.10.1.0/24/", struct{}{}, &actual)
+	if !phpipam.IsTokenExpired(err) {
+		t.Fatalf("Expected token expired error, got %v", err)
+	}
+
+	if calls != 1 {
+		t.Fatalf("Expected 1 request, got %d", calls)
+	}
+}
+
 func TestGetCustomFieldsSchema(t *testing.T) {
 	ts := httpCustomFieldsSchemaTestServer()
 	defer ts.Close()
@@ -414,7 +901,7 @@ func TestUpdateCustomFieldsRequest(t *testing.T) {
 	}
 
 	expected := testUpdateCustomFieldsRequestExpected
-	actual, err := client.updateCustomFieldsRequest(3, in, "subnets", testCustomFieldsSchemaExpected)
+	actual, err := client.updateCustomFieldsRequest(context.Background(), 3, in, "subnets", testCustomFieldsSchemaExpected)
 	if err != nil {
 		t.Fatalf("Bad: %s", err)
 	}
@@ -435,7 +922,7 @@ func TestUpdateCustomFieldsRequestIllegalField(t *testing.T) {
 		"Description": "sneaky",
 	}
 
-	_, err := client.updateCustomFieldsRequest(3, in, "subnets", testCustomFieldsSchemaExpected)
+	_, err := client.updateCustomFieldsRequest(context.Background(), 3, in, "subnets", testCustomFieldsSchemaExpected)
 	if err == nil {
 		t.Fatalf("Expected error, got none")
 	}
@@ -0,0 +1,111 @@
+package phpipam
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// APIError represents an error returned by the PHPIPAM API, or by the web
+// server in front of it.
+//
+// Use errors.As to get at the details of an error returned by the SDK, or one
+// of the Is* helpers in this package to check for common error conditions.
+type APIError struct {
+	// The HTTP status code of the response.
+	StatusCode int
+
+	// The HTTP status code with short-form message (ie: "404 Not Found").
+	Status string
+
+	// The result code from the API response body. This is zero if the response
+	// was not a PHPIPAM API response, such as an error page from a proxy.
+	Code int
+
+	// The error message from the API response body.
+	Message string
+
+	// The method of the request that failed.
+	Method string
+
+	// The URI of the request that failed, relative to the API endpoint and
+	// application ID.
+	URI string
+
+	// The raw response body.
+	Body []byte
+}
+
+// Error implements error for APIError.
+func (e *APIError) Error() string {
+	if e.Code == 0 {
+		return fmt.Sprintf("Non-API error (%s): %s", e.Status, e.Body)
+	}
+	return fmt.Sprintf("Error from API (%d): %s", e.Code, e.Message)
+}
+
+// hasCode returns true if either the API result code or the HTTP status code
+// matches code.
+func (e *APIError) hasCode(code int) bool {
+	return e.Code == code || e.StatusCode == code
+}
+
+// asAPIError unwraps err into an *APIError, if it is one.
+func asAPIError(err error) (*APIError, bool) {
+	var e *APIError
+	if errors.As(err, &e) {
+		return e, true
+	}
+	return nil, false
+}
+
+// IsNotFound returns true if err is an *APIError for a resource that could not
+// be found.
+func IsNotFound(err error) bool {
+	e, ok := asAPIError(err)
+	return ok && e.hasCode(http.StatusNotFound)
+}
+
+// IsConflict returns true if err is an *APIError signaling a conflict, such as
+// a resource that already exists.
+func IsConflict(err error) bool {
+	e, ok := asAPIError(err)
+	return ok && e.hasCode(http.StatusConflict)
+}
+
+// IsTokenExpired returns true if err is an *APIError signaling that the
+// session token used for the request has expired.
+func IsTokenExpired(err error) bool {
+	e, ok := asAPIError(err)
+	return ok && e.hasCode(http.StatusForbidden) && strings.EqualFold(e.Message, "Token expired")
+}
+
+// authFailureMessages are the messages PHPIPAM returns when credentials or
+// tokens are rejected. These do not always come with a 401 code - a bad
+// username or password is returned as a 500, for example.
+var authFailureMessages = []string{
+	"Invalid username or password",
+	"Invalid token",
+	"Please provide token",
+}
+
+// IsAuthFailure returns true if err is an *APIError signaling that the
+// supplied credentials or session token were rejected. Note that an expired
+// token is not considered an authentication failure - use IsTokenExpired to
+// check for that.
+func IsAuthFailure(err error) bool {
+	e, ok := asAPIError(err)
+	if !ok {
+		return false
+	}
+	if e.hasCode(http.StatusUnauthorized) {
+		return true
+	}
+	for _, m := range authFailureMessages {
+		if strings.EqualFold(e.Message, m) {
+			return true
+		}
+	}
+	return false
+}
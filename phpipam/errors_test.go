@@ -0,0 +1,115 @@
+package phpipam
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestAPIErrorError(t *testing.T) {
+	err := &APIError{
+		StatusCode: 404,
+		Status:     "404 Not Found",
+		Code:       404,
+		Message:    "No subnets found",
+	}
+
+	expected := "Error from API (404): No subnets found"
+	if err.Error() != expected {
+		t.Fatalf("Expected %q, got %q", expected, err.Error())
+	}
+}
+
+func TestAPIErrorErrorNonAPI(t *testing.T) {
+	err := &APIError{
+		StatusCode: 503,
+		Status:     "503 Service Unavailable",
+		Body:       []byte("Service Unavailable"),
+	}
+
+	expected := "Non-API error (503 Service Unavailable): Service Unavailable"
+	if err.Error() != expected {
+		t.Fatalf("Expected %q, got %q", expected, err.Error())
+	}
+}
+
+func TestErrorHelpers(t *testing.T) {
+	cases := []struct {
+		Name     string
+		Err      error
+		Check    func(error) bool
+		Expected bool
+	}{
+		{
+			Name:     "not found",
+			Err:      &APIError{StatusCode: 404, Code: 404, Message: "No subnets found"},
+			Check:    IsNotFound,
+			Expected: true,
+		},
+		{
+			Name:     "not found, wrapped",
+			Err:      fmt.Errorf("wrapped: %w", &APIError{StatusCode: 200, Code: 404, Message: "No subnets found"}),
+			Check:    IsNotFound,
+			Expected: true,
+		},
+		{
+			Name:     "not found, other code",
+			Err:      &APIError{StatusCode: 500, Code: 500, Message: "Invalid username or password"},
+			Check:    IsNotFound,
+			Expected: false,
+		},
+		{
+			Name:     "not found, not an API error",
+			Err:      fmt.Errorf("Error from API (404): No subnets found"),
+			Check:    IsNotFound,
+			Expected: false,
+		},
+		{
+			Name:     "conflict",
+			Err:      &APIError{StatusCode: 409, Code: 409, Message: "Subnet already exists"},
+			Check:    IsConflict,
+			Expected: true,
+		},
+		{
+			Name:     "token expired",
+			Err:      &APIError{StatusCode: 403, Code: 403, Message: "Token expired"},
+			Check:    IsTokenExpired,
+			Expected: true,
+		},
+		{
+			Name:     "token expired, invalid token",
+			Err:      &APIError{StatusCode: 403, Code: 403, Message: "Invalid token"},
+			Check:    IsTokenExpired,
+			Expected: false,
+		},
+		{
+			Name:     "auth failure, bad password",
+			Err:      &APIError{StatusCode: 500, Code: 500, Message: "Invalid username or password"},
+			Check:    IsAuthFailure,
+			Expected: true,
+		},
+		{
+			Name:     "auth failure, invalid token",
+			Err:      &APIError{StatusCode: 403, Code: 403, Message: "Invalid token"},
+			Check:    IsAuthFailure,
+			Expected: true,
+		},
+		{
+			Name:     "auth failure, unauthorized",
+			Err:      &APIError{StatusCode: 401, Status: "401 Unauthorized"},
+			Check:    IsAuthFailure,
+			Expected: true,
+		},
+		{
+			Name:     "auth failure, token expired",
+			Err:      &APIError{StatusCode: 403, Code: 403, Message: "Token expired"},
+			Check:    IsAuthFailure,
+			Expected: false,
+		},
+	}
+
+	for _, tc := range cases {
+		if actual := tc.Check(tc.Err); actual != tc.Expected {
+			t.Fatalf("%s: expected %t, got %t", tc.Name, tc.Expected, actual)
+		}
+	}
+}
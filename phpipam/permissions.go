@@ -0,0 +1,114 @@
+package phpipam
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+// AccessLevel is the level of access that a group has to a section or subnet.
+type AccessLevel int
+
+const (
+	// AccessNone grants no access.
+	AccessNone AccessLevel = iota
+
+	// AccessRead grants read-only access.
+	AccessRead
+
+	// AccessWrite grants read and write access.
+	AccessWrite
+
+	// AccessAdmin grants read, write, and administrative access.
+	AccessAdmin
+)
+
+// String implements fmt.Stringer for AccessLevel.
+func (a AccessLevel) String() string {
+	switch a {
+	case AccessNone:
+		return "none"
+	case AccessRead:
+		return "read"
+	case AccessWrite:
+		return "write"
+	case AccessAdmin:
+		return "admin"
+	}
+	return fmt.Sprintf("AccessLevel(%d)", int(a))
+}
+
+// Permissions is a map of group IDs to the access level that each group has
+// to a section or subnet.
+//
+// PHPIPAM represents permissions as a JSON object that is itself encoded as a
+// string, such as "{\"3\":\"1\",\"2\":\"2\"}". Permissions marshals to and from
+// this form.
+type Permissions map[int]AccessLevel
+
+// MarshalJSON implements json.Marshaler for the Permissions type.
+func (p Permissions) MarshalJSON() ([]byte, error) {
+	if p == nil {
+		return json.Marshal("")
+	}
+	b, err := json.Marshal(p.Groups())
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(string(b))
+}
+
+// UnmarshalJSON implements json.Unmarshaler for the Permissions type. Both the
+// stringified form and a plain JSON object are accepted.
+func (p *Permissions) UnmarshalJSON(b []byte) error {
+	if string(b) == "null" {
+		*p = nil
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(b, &s); err == nil {
+		if s == "" {
+			*p = nil
+			return nil
+		}
+		b = []byte(s)
+	}
+
+	var m map[string]interface{}
+	if err := json.Unmarshal(b, &m); err != nil {
+		return fmt.Errorf("Invalid permissions %s: %s", b, err)
+	}
+	out := make(Permissions, len(m))
+	for k, v := range m {
+		id, err := strconv.Atoi(k)
+		if err != nil {
+			return fmt.Errorf("Invalid group ID %q in permissions", k)
+		}
+		var level int
+		switch v := v.(type) {
+		case string:
+			level, err = strconv.Atoi(v)
+		case float64:
+			level = int(v)
+		default:
+			err = fmt.Errorf("unexpected type %T", v)
+		}
+		if err != nil || level < int(AccessNone) || level > int(AccessAdmin) {
+			return fmt.Errorf("Invalid access level %v for group %d in permissions", v, id)
+		}
+		out[id] = AccessLevel(level)
+	}
+	*p = out
+	return nil
+}
+
+// Groups returns the permissions as a map of stringified group IDs to
+// stringified access levels (i.e. {"3": "1"}). This is the form that the
+// permissions endpoints of the API take.
+func (p Permissions) Groups() map[string]string {
+	m := make(map[string]string, len(p))
+	for id, level := range p {
+		m[strconv.Itoa(id)] = strconv.Itoa(int(level))
+	}
+	return m
+}
@@ -0,0 +1,89 @@
+package phpipam
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+type testPermissionsType struct {
+	Permissions Permissions `json:"permissions,omitempty"`
+}
+
+const testPermissionsJSON = `{"permissions":"{\"2\":\"2\",\"3\":\"1\"}"}`
+
+var testPermissionsExpected = Permissions{
+	2: AccessWrite,
+	3: AccessRead,
+}
+
+func TestPermissionsMarshalJSON(t *testing.T) {
+	b, err := json.Marshal(testPermissionsType{Permissions: testPermissionsExpected})
+	if err != nil {
+		t.Fatalf("Bad: %s", err)
+	}
+	expected := testPermissionsJSON
+	actual := string(b)
+	if expected != actual {
+		t.Fatalf("Expected %s, got %s", expected, actual)
+	}
+}
+
+func TestPermissionsMarshalJSONOmitEmpty(t *testing.T) {
+	b, err := json.Marshal(testPermissionsType{})
+	if err != nil {
+		t.Fatalf("Bad: %s", err)
+	}
+	if string(b) != "{}" {
+		t.Fatalf("Expected {}, got %s", b)
+	}
+}
+
+func TestPermissionsUnmarshalJSON(t *testing.T) {
+	cases := map[string]Permissions{
+		testPermissionsJSON:               testPermissionsExpected,
+		`{"permissions":{"2":"2","3":1}}`: testPermissionsExpected,
+		`{"permissions":null}`:            nil,
+		`{"permissions":""}`:              nil,
+		`{"permissions":"{}"}`:            Permissions{},
+		`{"permissions":"{\"4\":\"0\"}"}`: Permissions{4: AccessNone},
+		`{"permissions":"{\"4\":\"3\"}"}`: Permissions{4: AccessAdmin},
+	}
+	for in, expected := range cases {
+		var actual testPermissionsType
+		if err := json.Unmarshal([]byte(in), &actual); err != nil {
+			t.Fatalf("%s: Bad: %s", in, err)
+		}
+		if !reflect.DeepEqual(expected, actual.Permissions) {
+			t.Fatalf("%s: Expected %#v, got %#v", in, expected, actual.Permissions)
+		}
+	}
+}
+
+func TestPermissionsUnmarshalJSONError(t *testing.T) {
+	for _, in := range []string{
+		`{"permissions":"{\"a\":\"1\"}"}`,
+		`{"permissions":"{\"3\":\"4\"}"}`,
+		`{"permissions":"{\"3\":\"x\"}"}`,
+		`{"permissions":"not json"}`,
+	} {
+		var v testPermissionsType
+		if err := json.Unmarshal([]byte(in), &v); err == nil {
+			t.Fatalf("%s: Expected error, got none", in)
+		}
+	}
+}
+
+func TestAccessLevelString(t *testing.T) {
+	for level, expected := range map[AccessLevel]string{
+		AccessNone:     "none",
+		AccessRead:     "read",
+		AccessWrite:    "write",
+		AccessAdmin:    "admin",
+		AccessLevel(9): "AccessLevel(9)",
+	} {
+		if actual := level.String(); expected != actual {
+			t.Fatalf("Expected %s, got %s", expected, actual)
+		}
+	}
+}
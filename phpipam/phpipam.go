@@ -3,16 +3,48 @@
 package phpipam
 
 import (
+	"crypto/tls"
+	"crypto/x509"
 	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
 	"os"
 	"reflect"
 	"strconv"
 	"strings"
+	"time"
 )
 
 // The default PHPIPAM API endpoint.
 const defaultAPIAddress = "http://localhost/api"
 
+// AuthMode represents the way the SDK authenticates to the PHPIPAM API. This
+// needs to match the security setting of the API application in PHPIPAM.
+type AuthMode string
+
+const (
+	// AuthModeUser logs in with a username and password via the user
+	// controller to obtain a session token, which is then refreshed as needed.
+	// This is the default.
+	AuthModeUser AuthMode = ""
+
+	// AuthModeAppCode sends the static app code token set in Config.AppCode
+	// with every request, for applications using the "SSL with App code token"
+	// security setting. No login is performed, and the token is never
+	// refreshed.
+	AuthModeAppCode AuthMode = "app_code"
+
+	// AuthModeCrypt encrypts every request with the app code set in
+	// Config.AppCode, for applications using the "Encrypted" security setting.
+	// As with AuthModeAppCode, no login is performed.
+	//
+	// Requests are encrypted in the format used by PHPIPAM's openssl backend
+	// (AES-128-CBC, authenticated with HMAC-SHA256). The legacy mcrypt format
+	// is not supported.
+	AuthModeCrypt AuthMode = "crypt"
+)
+
 // Config contains the configuration for connecting to the PHPIPAM API.
 //
 //
@@ -44,6 +76,110 @@ type Config struct {
 
 	// The user name for the PHPIPAM account.
 	Username string
+
+	// The way the SDK authenticates to the API. See AuthMode for more details.
+	AuthMode AuthMode
+
+	// The app code (token) of the API application. This is only used by the
+	// AuthModeAppCode and AuthModeCrypt authentication modes - in the latter,
+	// it's the encryption key for requests.
+	AppCode string
+
+	// An optional HTTP client to use for API requests. When this is set, it is
+	// used as-is for every request in the session (with the exception of
+	// redirects, which are never followed), and Transport, Timeout, and the TLS
+	// options below are ignored.
+	HTTPClient *http.Client
+
+	// An optional http.RoundTripper to use for API requests, for example to
+	// set up a proxy or tune connection pooling. When this is set, the TLS
+	// options below are ignored and need to be set on the transport itself.
+	Transport http.RoundTripper
+
+	// The overall timeout for a single HTTP request, including reading the
+	// response body. Zero means no timeout.
+	Timeout time.Duration
+
+	// The path to a PEM-encoded CA bundle to use to verify the API endpoint's
+	// certificate, instead of the system roots.
+	CACertFile string
+
+	// The paths to a PEM-encoded client certificate and key, for endpoints that
+	// require TLS client authentication. Both need to be set.
+	ClientCertFile string
+	ClientKeyFile  string
+
+	// Skips verification of the API endpoint's certificate. Do not use this
+	// in production.
+	InsecureSkipVerify bool
+
+	// The policy for retrying requests that fail with transient errors. By
+	// default, requests are not retried - see DefaultRetryPolicy for a
+	// reasonable starting point.
+	Retry RetryPolicy
+
+	// The maximum sustained rate of requests per second for a session. Zero
+	// means no limit. This applies to every HTTP request sent, including
+	// retries and logins.
+	RateLimit float64
+
+	// The number of requests that can be sent in a burst above RateLimit. If
+	// this is zero and RateLimit is set, a burst of 1 is used.
+	RateBurst int
+
+	// The maximum number of requests that can be in flight at once for a
+	// session. Zero means no limit.
+	MaxInFlight int
+
+	// How long before a session token expires it should be refreshed. Zero
+	// uses a default of one minute, and a negative value disables proactive
+	// refresh, in which case tokens are only refreshed after a request fails
+	// because the token has expired.
+	TokenRefreshSkew time.Duration
+
+	// The time zone of the PHPIPAM server. PHPIPAM returns token expiry times
+	// in the server's local time, without a time zone, so this is needed to
+	// work out exactly when tokens expire. When this is not set, the expiry
+	// time is read as UTC - see session.Session.TokenDeadline for details.
+	ServerTimeZone *time.Location
+}
+
+// TLSConfig builds a *tls.Config from the TLS options in the configuration.
+// If no TLS options are set, nil is returned, which means that the defaults
+// from the standard library are used.
+func (c Config) TLSConfig() (*tls.Config, error) {
+	if c.CACertFile == "" && c.ClientCertFile == "" && c.ClientKeyFile == "" && !c.InsecureSkipVerify {
+		return nil, nil
+	}
+
+	cfg := &tls.Config{
+		InsecureSkipVerify: c.InsecureSkipVerify,
+	}
+
+	if c.CACertFile != "" {
+		pem, err := ioutil.ReadFile(c.CACertFile)
+		if err != nil {
+			return nil, fmt.Errorf("Error reading CA certificate file: %s", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("No valid certificates found in CA certificate file %s", c.CACertFile)
+		}
+		cfg.RootCAs = pool
+	}
+
+	if c.ClientCertFile != "" || c.ClientKeyFile != "" {
+		if c.ClientCertFile == "" || c.ClientKeyFile == "" {
+			return nil, fmt.Errorf("Both ClientCertFile and ClientKeyFile need to be set for client certificate authentication")
+		}
+		cert, err := tls.LoadX509KeyPair(c.ClientCertFile, c.ClientKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("Error loading client certificate: %s", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+
+	return cfg, nil
 }
 
 // DefaultConfigProvider supplies a default configuration:
@@ -51,6 +187,11 @@ type Config struct {
 //  * Endpoint defaults to PHPIPAM_ENDPOINT_ADDR, otherwise http://localhost/api
 //  * Password defaults to PHPIPAM_PASSWORD, if set, otherwise empty
 //  * Username defaults to PHPIPAM_USER_NAME, if set, otherwise empty
+//  * AuthMode defaults to PHPIPAM_AUTH_MODE, if set, otherwise AuthModeUser
+//  * AppCode defaults to PHPIPAM_APP_CODE, if set, otherwise empty
+//  * CACertFile defaults to PHPIPAM_CA_CERT_FILE, if set, otherwise empty
+//  * InsecureSkipVerify defaults to true if PHPIPAM_INSECURE_SKIP_VERIFY is
+//    set to a true value (as per strconv.ParseBool), otherwise false
 //
 // This essentially loads an initial config state for any given
 // API service.
@@ -71,6 +212,14 @@ func DefaultConfigProvider() Config {
 			cfg.Password = d[1]
 		case "PHPIPAM_USER_NAME":
 			cfg.Username = d[1]
+		case "PHPIPAM_AUTH_MODE":
+			cfg.AuthMode = AuthMode(d[1])
+		case "PHPIPAM_APP_CODE":
+			cfg.AppCode = d[1]
+		case "PHPIPAM_CA_CERT_FILE":
+			cfg.CACertFile = d[1]
+		case "PHPIPAM_INSECURE_SKIP_VERIFY":
+			cfg.InsecureSkipVerify, _ = strconv.ParseBool(d[1])
 		}
 	}
 	return cfg
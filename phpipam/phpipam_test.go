@@ -2,6 +2,7 @@ package phpipam
 
 import (
 	"encoding/json"
+	"io/ioutil"
 	"os"
 	"testing"
 )
@@ -212,3 +213,68 @@ func TestJSONIntStringMarshalJSONOmitEmpty(t *testing.T) {
 		t.Fatalf("Expected %s, got %s", expected, actual)
 	}
 }
+
+func TestConfigTLSConfigNoOptions(t *testing.T) {
+	cfg := Config{}
+	actual, err := cfg.TLSConfig()
+	if err != nil {
+		t.Fatalf("Bad: %s", err)
+	}
+	if actual != nil {
+		t.Fatalf("Expected nil TLS config, got %#v", actual)
+	}
+}
+
+func TestConfigTLSConfigInsecureSkipVerify(t *testing.T) {
+	cfg := Config{
+		InsecureSkipVerify: true,
+	}
+	actual, err := cfg.TLSConfig()
+	if err != nil {
+		t.Fatalf("Bad: %s", err)
+	}
+	if !actual.InsecureSkipVerify {
+		t.Fatalf("Expected InsecureSkipVerify to be true")
+	}
+}
+
+func TestConfigTLSConfigBadCACertFile(t *testing.T) {
+	f, err := ioutil.TempFile("", "phpipam-ca")
+	if err != nil {
+		t.Fatalf("Bad: %s", err)
+	}
+	defer os.Remove(f.Name())
+	f.WriteString("not a certificate")
+	f.Close()
+
+	cfg := Config{
+		CACertFile: f.Name(),
+	}
+	if _, err := cfg.TLSConfig(); err == nil {
+		t.Fatalf("Expected error, got none")
+	}
+}
+
+func TestConfigTLSConfigClientCertWithoutKey(t *testing.T) {
+	cfg := Config{
+		ClientCertFile: "client.pem",
+	}
+	if _, err := cfg.TLSConfig(); err == nil {
+		t.Fatalf("Expected error, got none")
+	}
+}
+
+func TestPHPIPAMDefaultConfigProviderAppCode(t *testing.T) {
+	os.Setenv("PHPIPAM_AUTH_MODE", "app_code")
+	os.Setenv("PHPIPAM_APP_CODE", "appcode")
+	defer os.Unsetenv("PHPIPAM_AUTH_MODE")
+	defer os.Unsetenv("PHPIPAM_APP_CODE")
+
+	c := DefaultConfigProvider()
+	if c.AuthMode != AuthModeAppCode {
+		t.Fatalf("Expected AuthMode to be %s, got %s", AuthModeAppCode, c.AuthMode)
+	}
+	if c.AppCode != "appcode" {
+		t.Fatalf("Expected AppCode to be appcode, got %s", c.AppCode)
+	}
+}
@@ -0,0 +1,155 @@
+package request
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// validateCryptKey checks that key can be used as the encryption key for
+// encrypted requests.
+func validateCryptKey(key string) error {
+	if key == "" {
+		return fmt.Errorf("AppCode needs to be set to be used as an encryption key")
+	}
+	return nil
+}
+
+// cryptParams builds the parameters for an encrypted request. In encrypted
+// requests, the controller and IDs that are normally part of the URI are sent
+// as parameters instead, alongside the request data in bs.
+//
+// For example, a URI of /subnets/3/addresses/ becomes a controller parameter
+// of "subnets", an id parameter of "3", and an id2 parameter of "addresses".
+// Any query string in the URI is sent as additional parameters.
+func cryptParams(uri string, bs []byte) (map[string]interface{}, error) {
+	params := make(map[string]interface{})
+	if len(bs) > 0 && !bytes.Equal(bs, []byte("null")) {
+		if err := json.Unmarshal(bs, &params); err != nil {
+			return nil, fmt.Errorf("Request data needs to be a JSON object for encrypted requests: %s", err)
+		}
+	}
+
+	if i := strings.Index(uri, "?"); i >= 0 {
+		q, err := url.ParseQuery(uri[i+1:])
+		if err != nil {
+			return nil, fmt.Errorf("Invalid query string in URI %s: %s", uri, err)
+		}
+		for k := range q {
+			params[k] = q.Get(k)
+		}
+		uri = uri[:i]
+	}
+
+	for i, v := range strings.Split(strings.Trim(uri, "/"), "/") {
+		// Path segments, such as search terms, may be escaped.
+		if u, err := url.PathUnescape(v); err == nil {
+			v = u
+		}
+		switch i {
+		case 0:
+			params["controller"] = v
+		case 1:
+			params["id"] = v
+		default:
+			params[fmt.Sprintf("id%d", i)] = v
+		}
+	}
+	return params, nil
+}
+
+// cryptKey returns the AES-128 key for the app code appCode. As with PHP's
+// openssl_encrypt, which PHPIPAM uses on its end, the app code is truncated or
+// padded with zeros to the key length.
+func cryptKey(appCode string) []byte {
+	key := make([]byte, aes.BlockSize)
+	copy(key, appCode)
+	return key
+}
+
+// encryptRequest encrypts plaintext in the format that PHPIPAM's
+// Crypto::decrypt expects when using the openssl backend, with a random IV.
+// See encryptRequestIV.
+func encryptRequest(appCode string, plaintext []byte) (string, error) {
+	iv := make([]byte, aes.BlockSize)
+	if _, err := rand.Read(iv); err != nil {
+		return "", fmt.Errorf("Error generating IV for encrypted request: %s", err)
+	}
+	return encryptRequestIV(appCode, iv, plaintext)
+}
+
+// encryptRequestIV encrypts plaintext with AES-128 in CBC mode with PKCS#7
+// padding, using the app code as the key (see cryptKey) and the supplied IV.
+// An HMAC-SHA256 of the ciphertext, keyed with the full app code, is used to
+// authenticate the message.
+//
+// The result is the base64 encoding of the IV, the HMAC, and the ciphertext,
+// in that order.
+func encryptRequestIV(appCode string, iv, plaintext []byte) (string, error) {
+	block, err := aes.NewCipher(cryptKey(appCode))
+	if err != nil {
+		return "", err
+	}
+	if len(iv) != block.BlockSize() {
+		return "", fmt.Errorf("IV needs to be %d bytes long, got %d", block.BlockSize(), len(iv))
+	}
+	bs := block.BlockSize()
+	pad := bs - len(plaintext)%bs
+	ciphertext := make([]byte, len(plaintext)+pad)
+	copy(ciphertext, plaintext)
+	for i := len(plaintext); i < len(ciphertext); i++ {
+		ciphertext[i] = byte(pad)
+	}
+	cipher.NewCBCEncrypter(block, iv).CryptBlocks(ciphertext, ciphertext)
+
+	mac := hmac.New(sha256.New, []byte(appCode))
+	mac.Write(ciphertext)
+
+	buf := make([]byte, 0, len(iv)+sha256.Size+len(ciphertext))
+	buf = append(buf, iv...)
+	buf = append(buf, mac.Sum(nil)...)
+	buf = append(buf, ciphertext...)
+	return base64.StdEncoding.EncodeToString(buf), nil
+}
+
+// newCryptHTTPRequest creates a HTTP request in the PHPIPAM encrypted request
+// format, for API applications using the "Encrypted" security setting.
+//
+// The request data in bs is merged with the controller and IDs from the URI
+// (see cryptParams), encoded as JSON, encrypted with the app code as the key
+// (see encryptRequestIV), and sent along with the app ID as the enc_request and
+// app_id query parameters respectively. The request has no body, and no
+// authentication headers are sent, as the encryption key serves as the
+// authentication.
+//
+// Responses to encrypted requests are regular JSON responses, and are handled
+// the same as any other response.
+func (r *Request) newCryptHTTPRequest(ctx context.Context, bs []byte) (*http.Request, error) {
+	params, err := cryptParams(r.URI, bs)
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := json.Marshal(params)
+	if err != nil {
+		return nil, err
+	}
+	enc, err := encryptRequest(r.Session.Config.AppCode, plaintext)
+	if err != nil {
+		return nil, err
+	}
+
+	q := url.Values{}
+	q.Set("app_id", r.Session.Config.AppID)
+	q.Set("enc_request", enc)
+	return http.NewRequestWithContext(ctx, r.Method, fmt.Sprintf("%s/?%s", r.Session.Config.Endpoint, q.Encode()), nil)
+}
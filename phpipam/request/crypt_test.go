@@ -0,0 +1,238 @@
+package request
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/paybyphone/phpipam-sdk-go/phpipam"
+)
+
+const testCryptKey = "0123456789abcdef0123456789abcdef"
+
+// decryptRequest is a port of Crypto::decrypt_openssl from the PHPIPAM API,
+// which decrypts requests sent with encryptRequest.
+func decryptRequest(appCode, enc string) ([]byte, error) {
+	raw, err := base64.StdEncoding.DecodeString(enc)
+	if err != nil {
+		return nil, err
+	}
+	ivlen := aes.BlockSize
+	if len(raw) < ivlen+sha256.Size {
+		return nil, fmt.Errorf("encrypted request is too short")
+	}
+	iv := raw[:ivlen]
+	mac := raw[ivlen : ivlen+sha256.Size]
+	ciphertext := raw[ivlen+sha256.Size:]
+
+	calc := hmac.New(sha256.New, []byte(appCode))
+	calc.Write(ciphertext)
+	if !hmac.Equal(mac, calc.Sum(nil)) {
+		return nil, fmt.Errorf("HMAC mismatch")
+	}
+
+	// openssl_decrypt truncates or zero-pads the key to the cipher's key
+	// length.
+	key := make([]byte, 16)
+	copy(key, appCode)
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(ciphertext) == 0 || len(ciphertext)%aes.BlockSize != 0 {
+		return nil, fmt.Errorf("ciphertext is not a multiple of the block size")
+	}
+	buf := make([]byte, len(ciphertext))
+	cipher.NewCBCDecrypter(block, iv).CryptBlocks(buf, ciphertext)
+	pad := int(buf[len(buf)-1])
+	if pad < 1 || pad > aes.BlockSize {
+		return nil, fmt.Errorf("invalid padding")
+	}
+	return buf[:len(buf)-pad], nil
+}
+
+// httpCryptTestServer returns a stand-in for a PHPIPAM API in encrypted
+// request mode. It decrypts requests with testCryptKey, records the decrypted
+// parameters in params, and responds with okResponseText.
+func httpCryptTestServer(t *testing.T, params *map[string]interface{}) *httptest.Server {
+	return newHTTPTestServer(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Content-Type", "application/json")
+		if r.URL.Path != "/" {
+			t.Errorf("Expected request path to be /, got %s", r.URL.Path)
+		}
+		if r.URL.Query().Get("app_id") != "0123456789abcdefgh" {
+			t.Errorf("Unexpected app_id %q", r.URL.Query().Get("app_id"))
+		}
+		if r.Header.Get("phpipam-token") != "" || r.Header.Get("Authorization") != "" {
+			t.Errorf("Unexpected authentication headers in encrypted request")
+		}
+		plaintext, err := decryptRequest(testCryptKey, r.URL.Query().Get("enc_request"))
+		if err != nil {
+			http.Error(w, errorResponseText, http.StatusInternalServerError)
+			return
+		}
+		if err := json.Unmarshal(plaintext, params); err != nil {
+			http.Error(w, errorResponseText, http.StatusInternalServerError)
+			return
+		}
+		http.Error(w, okResponseText, http.StatusOK)
+	})
+}
+
+func TestCryptParams(t *testing.T) {
+	in := []byte(`{"subnetId":"3","description":"foo"}`)
+	expected := map[string]interface{}{
+		"controller":  "subnets",
+		"id":          "3",
+		"id2":         "addresses",
+		"subnetId":    "3",
+		"description": "foo",
+	}
+
+	actual, err := cryptParams("/subnets/3/addresses/", in)
+	if err != nil {
+		t.Fatalf("Bad: %s", err)
+	}
+
+	if !reflect.DeepEqual(expected, actual) {
+		t.Fatalf("Expected %#v, got %#v", expected, actual)
+	}
+}
+
+func TestCryptParamsQueryString(t *testing.T) {
+	expected := map[string]interface{}{
+		"controller": "search",
+		"id":         "foo",
+		"addresses":  "1",
+		"subnets":    "0",
+	}
+
+	actual, err := cryptParams("/search/foo/?addresses=1&subnets=0", nil)
+	if err != nil {
+		t.Fatalf("Bad: %s", err)
+	}
+
+	if !reflect.DeepEqual(expected, actual) {
+		t.Fatalf("Expected %#v, got %#v", expected, actual)
+	}
+}
+
+func TestCryptParamsEscapedPath(t *testing.T) {
+	expected := map[string]interface{}{
+		"controller": "search",
+		"id":         "10.10.1.0/24",
+	}
+
+	actual, err := cryptParams("/search/10.10.1.0%2F24/", nil)
+	if err != nil {
+		t.Fatalf("Bad: %s", err)
+	}
+
+	if !reflect.DeepEqual(expected, actual) {
+		t.Fatalf("Expected %#v, got %#v", expected, actual)
+	}
+}
+
+func TestCryptParamsNonObject(t *testing.T) {
+	if _, err := cryptParams("/subnets/", []byte(`"foo"`)); err == nil {
+		t.Fatalf("Expected error, got none")
+	}
+}
+
+func TestEncryptRequestIV(t *testing.T) {
+	// Generated with:
+	//
+	//   printf '{"controller":"sections"}' |
+	//     openssl enc -aes-128-cbc -K 30313233343536373839616263646566 \
+	//       -iv 66656463626139383736353433323130 > ct.bin
+	//   openssl dgst -sha256 -hmac 0123456789abcdef0123456789abcdef \
+	//     -binary ct.bin > mac.bin
+	//   printf fedcba9876543210 | cat - mac.bin ct.bin | base64
+	expected := "ZmVkY2JhOTg3NjU0MzIxMKAGgcGlHCUx06HazJXSFiLtbChlf6xm8bazj7LPnfWHEMjOYkmVWsTrN2+N91WlIUwH4n4ZqtvKOSDVO6HCFz4="
+
+	actual, err := encryptRequestIV(testCryptKey, []byte("fedcba9876543210"), []byte(`{"controller":"sections"}`))
+	if err != nil {
+		t.Fatalf("Bad: %s", err)
+	}
+
+	if expected != actual {
+		t.Fatalf("Expected %s, got %s", expected, actual)
+	}
+}
+
+func TestEncryptRequestRoundTrip(t *testing.T) {
+	for _, key := range []string{testCryptKey, "short"} {
+		for _, v := range []string{"", "a", "0123456789abcdef", `{"controller":"sections"}`} {
+			enc, err := encryptRequest(key, []byte(v))
+			if err != nil {
+				t.Fatalf("Bad: %s", err)
+			}
+			dec, err := decryptRequest(key, enc)
+			if err != nil {
+				t.Fatalf("Bad: %s", err)
+			}
+			if string(dec) != v {
+				t.Fatalf("Expected %q, got %q", v, dec)
+			}
+		}
+	}
+}
+
+func TestRequestSendCrypt(t *testing.T) {
+	var params map[string]interface{}
+	ts := httpCryptTestServer(t, &params)
+	defer ts.Close()
+	cfg := phpipamConfig()
+	cfg.Endpoint = ts.URL
+	cfg.AuthMode = phpipam.AuthModeCrypt
+	cfg.AppCode = testCryptKey
+	in := struct {
+		Name string `json:"name"`
+	}{
+		Name: "foo",
+	}
+	out := okAuthResponseData{}
+	r := testRequest(cfg, &in, &out)
+	r.Method = "PATCH"
+	r.URI = "/sections/4/"
+
+	if err := r.Send(); err != nil {
+		t.Fatalf("Unexpected request error: %s", err)
+	}
+
+	expectedParams := map[string]interface{}{
+		"controller": "sections",
+		"id":         "4",
+		"name":       "foo",
+	}
+	if !reflect.DeepEqual(expectedParams, params) {
+		t.Fatalf("Expected params %#v, got %#v", expectedParams, params)
+	}
+
+	expected := okResponse()
+	if !reflect.DeepEqual(expected, out) {
+		t.Fatalf("expected %v, got %v", expected, out)
+	}
+}
+
+func TestRequestSendCryptBadKey(t *testing.T) {
+	cfg := phpipamConfig()
+	cfg.Endpoint = "http://localhost/api"
+	cfg.AuthMode = phpipam.AuthModeCrypt
+	cfg.AppCode = ""
+	in := struct{}{}
+	out := okAuthResponseData{}
+	r := testRequest(cfg, &in, &out)
+
+	if err := r.Send(); err == nil {
+		t.Fatalf("Expected error, got success")
+	}
+}
@@ -3,11 +3,15 @@ package request
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"strconv"
+	"time"
 
+	"github.com/paybyphone/phpipam-sdk-go/phpipam"
 	"github.com/paybyphone/phpipam-sdk-go/phpipam/session"
 )
 
@@ -21,11 +25,48 @@ type APIResponse struct {
 	// Request.Output.
 	Data json.RawMessage
 
-	// The error message, if the request failed.
+	// The error message, if the request failed. Some successful requests,
+	// such as creates, set this as well.
 	Message string
 
 	// Whether or not the API request was successful.
 	Success bool
+
+	// The ID of the resource created by a POST request, if the API returned
+	// one. This is zero otherwise.
+	ID int
+
+	// The time the API took to process the request, in seconds.
+	Time float64
+}
+
+// UnmarshalJSON implements json.Unmarshaler for APIResponse. This is needed
+// as the ID of a created resource is returned as either a string or a
+// number, depending on the controller.
+func (r *APIResponse) UnmarshalJSON(b []byte) error {
+	type envelope APIResponse
+	var aux struct {
+		*envelope
+		ID json.RawMessage `json:"id"`
+	}
+	aux.envelope = (*envelope)(r)
+	if err := json.Unmarshal(b, &aux); err != nil {
+		return err
+	}
+
+	r.ID = 0
+	if len(aux.ID) == 0 || string(aux.ID) == "null" {
+		return nil
+	}
+	var id phpipam.JSONIntString
+	if err := json.Unmarshal(aux.ID, &id); err != nil {
+		if err := json.Unmarshal(aux.ID, &r.ID); err != nil {
+			return fmt.Errorf("Invalid ID %s in response", aux.ID)
+		}
+		return nil
+	}
+	r.ID = int(id)
+	return nil
 }
 
 // Request represents the API request.
@@ -45,6 +86,17 @@ type Request struct {
 	// The output of the request. This corresponds to the "data" field in a
 	// response.
 	Output interface{}
+
+	// Send the configured username and password via HTTP basic auth, even if
+	// the session has a token. This is used to log in.
+	BasicAuth bool
+
+	// The full response envelope, including the ID of any created resource.
+	// This is set after a successful request.
+	Response *APIResponse
+
+	// The headers of the response. This is set after a successful request.
+	ResponseHeader http.Header
 }
 
 // requestResponse is an unexported struct that encompasses status codes
@@ -57,8 +109,18 @@ type requestResponse struct {
 	// Status code with short-form message.
 	Status string
 
+	// Response headers.
+	Header http.Header
+
 	// Response body.
 	Body []byte
+
+	// The method and URI of the request, for error reporting.
+	Method string
+	URI    string
+
+	// The parsed response envelope, set by ReadResponseJSON.
+	Envelope *APIResponse
 }
 
 // BodyString converts requestResponse.Body to string.
@@ -83,6 +145,7 @@ func (r *requestResponse) ReadResponseJSON(v interface{}) error {
 	if !resp.Success {
 		return r.handleError()
 	}
+	r.Envelope = &resp
 
 	if string(resp.Data) != "" {
 		if err := json.Unmarshal(resp.Data, v); err != nil {
@@ -92,33 +155,69 @@ func (r *requestResponse) ReadResponseJSON(v interface{}) error {
 	return nil
 }
 
-// handleError handles a PHPIPAM API error response.
+// handleError handles a PHPIPAM API error response, returning a
+// *phpipam.APIError.
 func (r *requestResponse) handleError() error {
+	e := &phpipam.APIError{
+		StatusCode: r.StatusCode,
+		Status:     r.Status,
+		Method:     r.Method,
+		URI:        r.URI,
+		Body:       r.Body,
+	}
 	var resp APIResponse
 	if err := json.Unmarshal(r.Body, &resp); err != nil {
-		// more than likely not JSON, just pull together the body and return it as
-		// the error message
-		return fmt.Errorf("Non-API error (%s): %s", r.Status, r.BodyString())
+		// more than likely not JSON, so there is no code or message to extract.
+		// The body is rendered as the error message.
+		return e
 	}
 
-	// Return a properly formatted error from the appropraite fields.
-	return fmt.Errorf("Error from API (%d): %s", resp.Code, resp.Message)
+	e.Code = resp.Code
+	e.Message = resp.Message
+	return e
 }
 
 // newRequestResponse creates a new requestResponse instance off a HTTP
 // response. Warning: This also closes the Body.
-func newRequestResponse(r *http.Response) *requestResponse {
+//
+// An error is returned if the body could not be read in full, which can
+// happen if the request's context is cancelled while the body is in transit.
+func newRequestResponse(r *http.Response) (*requestResponse, error) {
 	rr := &requestResponse{
 		StatusCode: r.StatusCode,
 		Status:     r.Status,
+		Header:     r.Header,
 	}
 	defer r.Body.Close()
 	body, err := ioutil.ReadAll(r.Body)
 	if err != nil {
-		panic(err)
+		return nil, err
 	}
 	rr.Body = body
-	return rr
+	return rr, nil
+}
+
+// retryAfter returns the delay requested by a response's Retry-After header,
+// which can either be a number of seconds or a HTTP date. false is returned if
+// the header is missing or invalid.
+func retryAfter(h http.Header, now time.Time) (time.Duration, bool) {
+	v := h.Get("Retry-After")
+	if v == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(v); err == nil {
+		if secs < 0 {
+			return 0, false
+		}
+		return time.Duration(secs) * time.Second, true
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		if d := t.Sub(now); d > 0 {
+			return d, true
+		}
+		return 0, true
+	}
+	return 0, false
 }
 
 // Send sends a request to the API endpoint, and parsees the response.
@@ -126,50 +225,101 @@ func newRequestResponse(r *http.Response) *requestResponse {
 // Note that by design, Send does not handle redirects - if you get a 302 error
 // or some other sort of 300 error from the SDK, please check your API
 // endpoints.
+//
+// Requests that fail with transient errors are retried according to the
+// session's retry policy. See phpipam.RetryPolicy for more details. A delay
+// requested by the API via a Retry-After header is honoured, unless it is
+// longer than the policy's MaxBackoff, in which case the request is not
+// retried and the error is returned.
 func (r *Request) Send() error {
-	var req *http.Request
-	var err error
-	client := &http.Client{
-		CheckRedirect: func(req *http.Request, via []*http.Request) error {
-			return http.ErrUseLastResponse
-		},
+	return r.SendWithContext(context.Background())
+}
+
+// SendWithContext is the same as Send, but takes a context.Context that can be
+// used to cancel the request or set a deadline on it. A nil context is
+// treated as context.Background(). The context is also honoured while waiting
+// to retry a request, and while waiting on the session's rate limit and
+// in-flight request cap, which apply to each attempt.
+func (r *Request) SendWithContext(ctx context.Context) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	client, err := r.Session.HTTPClient()
+	if err != nil {
+		return fmt.Errorf("Error setting up HTTP client: %s", err)
 	}
 
+	switch r.Session.Config.AuthMode {
+	case phpipam.AuthModeUser:
+	case phpipam.AuthModeAppCode:
+		if r.Session.Config.AppCode == "" {
+			return fmt.Errorf("AppCode needs to be set when using the %s authentication mode", r.Session.Config.AuthMode)
+		}
+	case phpipam.AuthModeCrypt:
+		if err := validateCryptKey(r.Session.Config.AppCode); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("Unsupported authentication mode %q", r.Session.Config.AuthMode)
+	}
+
+	var bs []byte
 	switch r.Method {
 	case "OPTIONS", "GET", "POST", "PUT", "PATCH", "DELETE":
-		bs, err := json.Marshal(r.Input)
+		bs, err = json.Marshal(r.Input)
 		if err != nil {
 			return fmt.Errorf("Error preparing request data: %s", err)
 		}
-		buf := bytes.NewBuffer(bs)
-		req, err = http.NewRequest(r.Method, fmt.Sprintf("%s/%s%s", r.Session.Config.Endpoint, r.Session.Config.AppID, r.URI), buf)
-		req.Header.Add("Content-Type", "application/json")
 	default:
 		return fmt.Errorf("API request method %s not supported by PHPIPAM", r.Method)
 	}
 
-	if err != nil {
-		panic(err)
-	}
+	policy := r.Session.Config.Retry
+	var resp *requestResponse
+	for attempt := 1; ; attempt++ {
+		resp, err = r.do(ctx, client, bs)
+		if err != nil && ctx.Err() != nil {
+			// Surface cancellation and deadline errors as-is so that callers can
+			// check for them with errors.Is. A response that made it back before
+			// the context was done is still used.
+			return ctx.Err()
+		}
 
-	// Add session token if it exists, otherwise append username/password from the config.
-	// Note that according to the PHPIPAM docs, Basic Auth does not work on
-	// anything else other than the user controller. Falling back to basic auth
-	// should only be used for setting up the session only.
-	if r.Session.Token.String != "" {
-		req.Header.Add("phpipam-token", r.Session.Token.String)
-	} else {
-		req.SetBasicAuth(r.Session.Config.Username, r.Session.Config.Password)
-	}
+		var statusCode int
+		if err == nil {
+			statusCode = resp.StatusCode
+			if statusCode < 300 {
+				break
+			}
+		}
+		if !policy.ShouldRetry(r.Method, attempt, statusCode) {
+			break
+		}
 
-	re, err := client.Do(req)
+		delay := policy.Backoff(attempt)
+		if resp != nil {
+			if d, ok := retryAfter(resp.Header, time.Now()); ok {
+				// Don't wait longer than MaxBackoff on the server's say-so - give up
+				// and return the error instead.
+				if policy.MaxBackoff > 0 && d > policy.MaxBackoff {
+					break
+				}
+				delay = d
+			}
+		}
+		t := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return ctx.Err()
+		case <-t.C:
+		}
+	}
 
 	if err != nil {
-		return fmt.Errorf("HTTP protocol error: %s", err)
+		return err
 	}
 
-	resp := newRequestResponse(re)
-
 	// A response code of 300 or higher is an error. We do not handle redirects.
 	if resp.StatusCode >= 300 {
 		return resp.handleError()
@@ -180,10 +330,69 @@ func (r *Request) Send() error {
 	if err := resp.ReadResponseJSON(r.Output); err != nil {
 		return err
 	}
+	r.Response = resp.Envelope
+	r.ResponseHeader = resp.Header
 
 	return nil
 }
 
+// do performs a single HTTP round trip for the request, with the request body
+// supplied in bs. The response is returned regardless of its status code - an
+// error is only returned if no complete response was received.
+//
+// Every round trip, including retries, waits on the session's rate limit and
+// in-flight request cap.
+func (r *Request) do(ctx context.Context, client *http.Client, bs []byte) (*requestResponse, error) {
+	release, err := r.Session.Acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	var req *http.Request
+	if r.Session.Config.AuthMode == phpipam.AuthModeCrypt {
+		req, err = r.newCryptHTTPRequest(ctx, bs)
+	} else {
+		req, err = http.NewRequestWithContext(ctx, r.Method, fmt.Sprintf("%s/%s%s", r.Session.Config.Endpoint, r.Session.Config.AppID, r.URI), bytes.NewBuffer(bs))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Error preparing request: %s", err)
+	}
+
+	switch r.Session.Config.AuthMode {
+	case phpipam.AuthModeCrypt:
+		// Encrypted requests carry no body or authentication headers.
+	case phpipam.AuthModeAppCode:
+		req.Header.Add("Content-Type", "application/json")
+		// The app code is sent as a static token on every request.
+		req.Header.Add("token", r.Session.Config.AppCode)
+	default:
+		req.Header.Add("Content-Type", "application/json")
+		// Add session token if it exists, otherwise append username/password from the config.
+		// Note that according to the PHPIPAM docs, Basic Auth does not work on
+		// anything else other than the user controller. Falling back to basic auth
+		// should only be used for setting up the session only.
+		if token := r.Session.CurrentToken(); token.String != "" && !r.BasicAuth {
+			req.Header.Add("phpipam-token", token.String)
+		} else {
+			req.SetBasicAuth(r.Session.Config.Username, r.Session.Config.Password)
+		}
+	}
+
+	re, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("HTTP protocol error: %s", err)
+	}
+
+	resp, err := newRequestResponse(re)
+	if err != nil {
+		return nil, fmt.Errorf("Error reading response body: %s", err)
+	}
+	resp.Method = r.Method
+	resp.URI = r.URI
+	return resp, nil
+}
+
 // NewRequest creates a new request instance with configuration set.
 func NewRequest(s *session.Session) *Request {
 	r := &Request{
@@ -1,13 +1,21 @@
 package request
 
 import (
+	"context"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
 	"fmt"
+	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"os"
 	"reflect"
 	"regexp"
 	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/paybyphone/phpipam-sdk-go/phpipam"
 	"github.com/paybyphone/phpipam-sdk-go/phpipam/session"
@@ -78,6 +86,42 @@ func httpOKTestServer() *httptest.Server {
 	})
 }
 
+// httpHangingTestServer returns a server that does not respond until the
+// supplied channel is closed.
+func httpHangingTestServer(done chan struct{}) *httptest.Server {
+	return newHTTPTestServer(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-done:
+		case <-r.Context().Done():
+		}
+	})
+}
+
+// httpFlakyTestServer returns a server that fails the first failures
+// requests with the supplied status code, and succeeds afterwards. The number
+// of requests received is recorded in calls.
+func httpFlakyTestServer(failures, status int, calls *int32) *httptest.Server {
+	return newHTTPTestServer(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(calls, 1)
+		if int(n) <= failures {
+			w.Header().Add("Content-Type", "text/html")
+			http.Error(w, errorResponseNonJSONText, status)
+			return
+		}
+		w.Header().Add("Content-Type", "application/json")
+		http.Error(w, okResponseText, http.StatusOK)
+	})
+}
+
+func testRetryPolicy() phpipam.RetryPolicy {
+	return phpipam.RetryPolicy{
+		MaxAttempts: 3,
+		MinBackoff:  time.Millisecond,
+		MaxBackoff:  10 * time.Millisecond,
+		Jitter:      0.5,
+	}
+}
+
 func phpipamConfig() phpipam.Config {
 	return phpipam.Config{
 		AppID:    "0123456789abcdefgh",
@@ -138,6 +182,25 @@ func TestRequestSendError(t *testing.T) {
 	if err.Error() != expected {
 		t.Fatalf("expected %s, got %s", expected, err)
 	}
+
+	var apiErr *phpipam.APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("expected error to be a *phpipam.APIError, got %T", err)
+	}
+
+	expectedAPIErr := &phpipam.APIError{
+		StatusCode: http.StatusInternalServerError,
+		Status:     "500 Internal Server Error",
+		Code:       500,
+		Message:    "Invalid username or password",
+		Method:     "GET",
+		URI:        "/api/test/users/",
+		Body:       apiErr.Body,
+	}
+
+	if !reflect.DeepEqual(expectedAPIErr, apiErr) {
+		t.Fatalf("expected %#v, got %#v", expectedAPIErr, apiErr)
+	}
 }
 
 func TestRequestSendNonJSONError(t *testing.T) {
@@ -182,3 +245,453 @@ func TestRequestSendProtocolError(t *testing.T) {
 		t.Fatalf("expected error to match %s, got %s", expected, err)
 	}
 }
+
+func TestRequestSendWithContextDeadline(t *testing.T) {
+	done := make(chan struct{})
+	ts := httpHangingTestServer(done)
+	defer ts.Close()
+	defer close(done)
+	cfg := phpipamConfig()
+	cfg.Endpoint = ts.URL
+	in := struct{}{}
+	out := okAuthResponseData{}
+	r := testRequest(cfg, &in, &out)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	err := r.SendWithContext(ctx)
+
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected %s, got %v", context.DeadlineExceeded, err)
+	}
+}
+
+func TestRequestSendWithContextCancelled(t *testing.T) {
+	ts := httpOKTestServer()
+	defer ts.Close()
+	cfg := phpipamConfig()
+	cfg.Endpoint = ts.URL
+	in := struct{}{}
+	out := okAuthResponseData{}
+	r := testRequest(cfg, &in, &out)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := r.SendWithContext(ctx)
+
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected %s, got %v", context.Canceled, err)
+	}
+}
+
+// roundTripFunc adapts a function to http.RoundTripper.
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+func TestRequestSendWithContextCancelledAfterResponse(t *testing.T) {
+	ts := httpOKTestServer()
+	defer ts.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	cfg := phpipamConfig()
+	cfg.Endpoint = ts.URL
+	// Read the whole response, then cancel the context before handing the
+	// response back, as if the context was done just after the response
+	// arrived.
+	cfg.Transport = roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		resp, err := http.DefaultTransport.RoundTrip(r)
+		if err != nil {
+			return nil, err
+		}
+		body, err := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		resp.Body = ioutil.NopCloser(strings.NewReader(string(body)))
+		cancel()
+		return resp, nil
+	})
+	in := struct{}{}
+	out := okAuthResponseData{}
+	r := testRequest(cfg, &in, &out)
+
+	if err := r.SendWithContext(ctx); err != nil {
+		t.Fatalf("Unexpected request error: %s", err)
+	}
+
+	expected := okResponse()
+	if !reflect.DeepEqual(expected, out) {
+		t.Fatalf("expected %v, got %v", expected, out)
+	}
+}
+
+func TestRequestSendCACertFile(t *testing.T) {
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Content-Type", "application/json")
+		http.Error(w, okResponseText, http.StatusOK)
+	}))
+	defer ts.Close()
+
+	f, err := ioutil.TempFile("", "phpipam-ca")
+	if err != nil {
+		t.Fatalf("Bad: %s", err)
+	}
+	defer os.Remove(f.Name())
+	pem.Encode(f, &pem.Block{Type: "CERTIFICATE", Bytes: ts.Certificate().Raw})
+	f.Close()
+
+	cfg := phpipamConfig()
+	cfg.Endpoint = ts.URL
+	cfg.CACertFile = f.Name()
+	in := struct{}{}
+	out := okAuthResponseData{}
+	r := testRequest(cfg, &in, &out)
+
+	if err := r.Send(); err != nil {
+		t.Fatalf("Unexpected request error: %s", err)
+	}
+
+	expected := okResponse()
+
+	if reflect.DeepEqual(expected, out) == false {
+		t.Fatalf("expected %v, got %v", expected, out)
+	}
+}
+
+func TestRequestSendUnknownCA(t *testing.T) {
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Content-Type", "application/json")
+		http.Error(w, okResponseText, http.StatusOK)
+	}))
+	defer ts.Close()
+
+	cfg := phpipamConfig()
+	cfg.Endpoint = ts.URL
+	in := struct{}{}
+	out := okAuthResponseData{}
+	r := testRequest(cfg, &in, &out)
+
+	if err := r.Send(); err == nil {
+		t.Fatalf("Expected certificate verification error, got success")
+	}
+}
+
+func TestRequestSendRetrySuccess(t *testing.T) {
+	var calls int32
+	ts := httpFlakyTestServer(2, http.StatusServiceUnavailable, &calls)
+	defer ts.Close()
+	cfg := phpipamConfig()
+	cfg.Endpoint = ts.URL
+	cfg.Retry = testRetryPolicy()
+	in := struct{}{}
+	out := okAuthResponseData{}
+	r := testRequest(cfg, &in, &out)
+
+	if err := r.Send(); err != nil {
+		t.Fatalf("Unexpected request error: %s", err)
+	}
+
+	if calls != 3 {
+		t.Fatalf("Expected 3 calls, got %d", calls)
+	}
+
+	expected := okResponse()
+
+	if reflect.DeepEqual(expected, out) == false {
+		t.Fatalf("expected %v, got %v", expected, out)
+	}
+}
+
+func TestRequestSendRetryExhausted(t *testing.T) {
+	var calls int32
+	ts := httpFlakyTestServer(5, http.StatusBadGateway, &calls)
+	defer ts.Close()
+	cfg := phpipamConfig()
+	cfg.Endpoint = ts.URL
+	cfg.Retry = testRetryPolicy()
+	in := struct{}{}
+	out := okAuthResponseData{}
+	r := testRequest(cfg, &in, &out)
+
+	err := r.Send()
+	var apiErr *phpipam.APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusBadGateway {
+		t.Fatalf("Expected a 502 error, got %v", err)
+	}
+
+	if calls != 3 {
+		t.Fatalf("Expected 3 calls, got %d", calls)
+	}
+}
+
+func TestRequestSendRetryNonIdempotent(t *testing.T) {
+	var calls int32
+	ts := httpFlakyTestServer(1, http.StatusServiceUnavailable, &calls)
+	defer ts.Close()
+	cfg := phpipamConfig()
+	cfg.Endpoint = ts.URL
+	cfg.Retry = testRetryPolicy()
+	in := struct{}{}
+	out := okAuthResponseData{}
+	r := testRequest(cfg, &in, &out)
+	r.Method = "POST"
+
+	if err := r.Send(); err == nil {
+		t.Fatalf("Expected error, got success")
+	}
+
+	if calls != 1 {
+		t.Fatalf("Expected 1 call, got %d", calls)
+	}
+}
+
+func TestRequestSendRetryProtocolError(t *testing.T) {
+	var calls int32
+	ts := newHTTPTestServer(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			// Drop the connection without a response.
+			conn, _, err := w.(http.Hijacker).Hijack()
+			if err == nil {
+				conn.Close()
+			}
+			return
+		}
+		w.Header().Add("Content-Type", "application/json")
+		http.Error(w, okResponseText, http.StatusOK)
+	})
+	defer ts.Close()
+	cfg := phpipamConfig()
+	cfg.Endpoint = ts.URL
+	cfg.Retry = testRetryPolicy()
+	in := struct{}{}
+	out := okAuthResponseData{}
+	r := testRequest(cfg, &in, &out)
+
+	if err := r.Send(); err != nil {
+		t.Fatalf("Unexpected request error: %s", err)
+	}
+
+	if calls != 2 {
+		t.Fatalf("Expected 2 calls, got %d", calls)
+	}
+}
+
+func TestRequestSendRetryContextCancelled(t *testing.T) {
+	var calls int32
+	ts := httpFlakyTestServer(5, http.StatusServiceUnavailable, &calls)
+	defer ts.Close()
+	cfg := phpipamConfig()
+	cfg.Endpoint = ts.URL
+	cfg.Retry = testRetryPolicy()
+	cfg.Retry.MinBackoff = time.Minute
+	cfg.Retry.MaxBackoff = time.Minute
+	in := struct{}{}
+	out := okAuthResponseData{}
+	r := testRequest(cfg, &in, &out)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	err := r.SendWithContext(ctx)
+
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected %s, got %v", context.DeadlineExceeded, err)
+	}
+
+	if calls != 1 {
+		t.Fatalf("Expected 1 call, got %d", calls)
+	}
+}
+
+// httpRetryAfterTestServer returns a server that fails the first request with
+// a 503 and the supplied Retry-After header, and succeeds afterwards. The
+// number of requests received is recorded in calls.
+func httpRetryAfterTestServer(retryAfter string, calls *int32) *httptest.Server {
+	return newHTTPTestServer(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(calls, 1) == 1 {
+			w.Header().Add("Content-Type", "text/html")
+			w.Header().Add("Retry-After", retryAfter)
+			http.Error(w, errorResponseNonJSONText, http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Add("Content-Type", "application/json")
+		http.Error(w, okResponseText, http.StatusOK)
+	})
+}
+
+func TestRequestSendRetryAfter(t *testing.T) {
+	var calls int32
+	ts := httpRetryAfterTestServer("1", &calls)
+	defer ts.Close()
+	cfg := phpipamConfig()
+	cfg.Endpoint = ts.URL
+	cfg.Retry = testRetryPolicy()
+	cfg.Retry.MaxBackoff = 2 * time.Second
+	in := struct{}{}
+	out := okAuthResponseData{}
+	r := testRequest(cfg, &in, &out)
+
+	start := time.Now()
+	if err := r.Send(); err != nil {
+		t.Fatalf("Unexpected request error: %s", err)
+	}
+
+	if calls != 2 {
+		t.Fatalf("Expected 2 calls, got %d", calls)
+	}
+	if d := time.Since(start); d < time.Second {
+		t.Fatalf("Expected Retry-After delay of 1s to be honoured, took %s", d)
+	}
+}
+
+func TestRequestSendRetryAfterAboveMaxBackoff(t *testing.T) {
+	var calls int32
+	ts := httpRetryAfterTestServer("86400", &calls)
+	defer ts.Close()
+	cfg := phpipamConfig()
+	cfg.Endpoint = ts.URL
+	cfg.Retry = testRetryPolicy()
+	in := struct{}{}
+	out := okAuthResponseData{}
+	r := testRequest(cfg, &in, &out)
+
+	start := time.Now()
+	err := r.Send()
+	var apiErr *phpipam.APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("Expected 503 API error, got %v", err)
+	}
+
+	if calls != 1 {
+		t.Fatalf("Expected 1 call, got %d", calls)
+	}
+	if d := time.Since(start); d > 5*time.Second {
+		t.Fatalf("Expected request to fail without waiting, took %s", d)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2017, 3, 3, 0, 0, 0, 0, time.UTC)
+
+	cases := []struct {
+		Value         string
+		ExpectedDelay time.Duration
+		ExpectedOK    bool
+	}{
+		{"", 0, false},
+		{"5", 5 * time.Second, true},
+		{"-1", 0, false},
+		{"Fri, 03 Mar 2017 00:00:30 GMT", 30 * time.Second, true},
+		{"Thu, 02 Mar 2017 00:00:00 GMT", 0, true},
+		{"soon", 0, false},
+	}
+
+	for _, tc := range cases {
+		h := http.Header{}
+		if tc.Value != "" {
+			h.Set("Retry-After", tc.Value)
+		}
+		d, ok := retryAfter(h, now)
+		if d != tc.ExpectedDelay || ok != tc.ExpectedOK {
+			t.Fatalf("%q: expected (%s, %t), got (%s, %t)", tc.Value, tc.ExpectedDelay, tc.ExpectedOK, d, ok)
+		}
+	}
+}
+
+func TestRequestSendAppCodeMissing(t *testing.T) {
+	cfg := phpipamConfig()
+	cfg.Endpoint = "http://localhost/api"
+	cfg.AuthMode = phpipam.AuthModeAppCode
+	in := struct{}{}
+	out := okAuthResponseData{}
+	r := testRequest(cfg, &in, &out)
+
+	if err := r.Send(); err == nil {
+		t.Fatalf("Expected error, got success")
+	}
+}
+
+func TestRequestSendUnknownAuthMode(t *testing.T) {
+	cfg := phpipamConfig()
+	cfg.Endpoint = "http://localhost/api"
+	cfg.AuthMode = "bogus"
+	in := struct{}{}
+	out := okAuthResponseData{}
+	r := testRequest(cfg, &in, &out)
+
+	if err := r.Send(); err == nil {
+		t.Fatalf("Expected error, got success")
+	}
+}
+
+func TestAPIResponseUnmarshalJSON(t *testing.T) {
+	cases := []struct {
+		Body     string
+		Expected APIResponse
+	}{
+		{
+			Body: `{"code":201,"success":true,"message":"Subnet created","id":"12","time":0.5}`,
+			Expected: APIResponse{
+				Code:    201,
+				Success: true,
+				Message: "Subnet created",
+				ID:      12,
+				Time:    0.5,
+			},
+		},
+		{
+			Body: `{"code":201,"success":true,"id":13,"data":"Address created"}`,
+			Expected: APIResponse{
+				Code:    201,
+				Success: true,
+				ID:      13,
+				Data:    []byte(`"Address created"`),
+			},
+		},
+		{
+			Body: `{"code":200,"success":true,"id":null}`,
+			Expected: APIResponse{
+				Code:    200,
+				Success: true,
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		var actual APIResponse
+		if err := json.Unmarshal([]byte(tc.Body), &actual); err != nil {
+			t.Fatalf("%s: bad: %s", tc.Body, err)
+		}
+		if !reflect.DeepEqual(tc.Expected, actual) {
+			t.Fatalf("%s: expected %#v, got %#v", tc.Body, tc.Expected, actual)
+		}
+	}
+}
+
+func TestAPIResponseUnmarshalJSONBadID(t *testing.T) {
+	var actual APIResponse
+	if err := json.Unmarshal([]byte(`{"code":201,"success":true,"id":"foo"}`), &actual); err == nil {
+		t.Fatalf("Expected error, got none")
+	}
+}
+
+func TestRequestSendResponse(t *testing.T) {
+	ts := httpOKTestServer()
+	defer ts.Close()
+	cfg := phpipamConfig()
+	cfg.Endpoint = ts.URL
+	in := struct{}{}
+	out := okAuthResponseData{}
+	r := testRequest(cfg, &in, &out)
+
+	if err := r.Send(); err != nil {
+		t.Fatalf("Unexpected request error: %s", err)
+	}
+
+	if r.Response == nil || r.Response.Code != 200 || !r.Response.Success {
+		t.Fatalf("Expected response envelope to be set, got %#v", r.Response)
+	}
+}
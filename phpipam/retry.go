@@ -0,0 +1,120 @@
+package phpipam
+
+import (
+	"math/rand"
+	"net/http"
+	"time"
+)
+
+// RetryPolicy controls how requests that fail with a transient error are
+// retried. The zero value disables retries.
+//
+// A request is retried when the HTTP request fails outright (ie: the
+// connection was reset), or when the response carries one of the status codes
+// in RetryableStatusCodes. Only idempotent methods (GET, OPTIONS, PUT, and
+// DELETE) are retried unless RetryNonIdempotent is set.
+type RetryPolicy struct {
+	// The maximum number of attempts for a request, including the first one.
+	// Values of 1 or lower disable retries.
+	MaxAttempts int
+
+	// The delay before the first retry. This is doubled for every subsequent
+	// retry, up to MaxBackoff.
+	MinBackoff time.Duration
+
+	// The upper limit of the delay between retries. Zero means no limit. This
+	// also caps the delay requested by a Retry-After header - if the API asks
+	// for a longer delay, the request is not retried.
+	MaxBackoff time.Duration
+
+	// The fraction of the delay, between 0 and 1, that is randomized to stop
+	// many clients from retrying in lock step. For example, a jitter of 0.2
+	// with a delay of 1s yields a delay of somewhere between 800ms and 1s.
+	Jitter float64
+
+	// The HTTP status codes that are considered transient. If this is empty,
+	// 429, 502, 503, and 504 are used.
+	RetryableStatusCodes []int
+
+	// Allow POST and PATCH requests to be retried as well. Note that this can
+	// result in duplicate resources if a request that was actually processed
+	// by the API is retried.
+	RetryNonIdempotent bool
+}
+
+// defaultRetryableStatusCodes are the status codes used when
+// RetryPolicy.RetryableStatusCodes is empty.
+var defaultRetryableStatusCodes = []int{
+	http.StatusTooManyRequests,
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+}
+
+// DefaultRetryPolicy returns a RetryPolicy suitable for most uses: up to 3
+// attempts for idempotent requests, starting at a 250ms delay and backing off
+// to at most 5 seconds, with 20% jitter.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts: 3,
+		MinBackoff:  250 * time.Millisecond,
+		MaxBackoff:  5 * time.Second,
+		Jitter:      0.2,
+	}
+}
+
+// ShouldRetry returns true if a request with the supplied method that failed
+// on the supplied attempt (starting at 1) should be tried again. statusCode
+// should be zero if the request failed without a response.
+func (p RetryPolicy) ShouldRetry(method string, attempt, statusCode int) bool {
+	if attempt >= p.MaxAttempts {
+		return false
+	}
+
+	switch method {
+	case "GET", "OPTIONS", "PUT", "DELETE":
+	default:
+		if !p.RetryNonIdempotent {
+			return false
+		}
+	}
+
+	if statusCode == 0 {
+		return true
+	}
+
+	codes := p.RetryableStatusCodes
+	if len(codes) == 0 {
+		codes = defaultRetryableStatusCodes
+	}
+	for _, c := range codes {
+		if c == statusCode {
+			return true
+		}
+	}
+	return false
+}
+
+// Backoff returns the delay to wait before retrying a request that failed on
+// the supplied attempt (starting at 1).
+func (p RetryPolicy) Backoff(attempt int) time.Duration {
+	d := p.MinBackoff
+	for i := 1; i < attempt; i++ {
+		d *= 2
+		if p.MaxBackoff > 0 && d >= p.MaxBackoff {
+			break
+		}
+	}
+	if p.MaxBackoff > 0 && d > p.MaxBackoff {
+		d = p.MaxBackoff
+	}
+
+	if p.Jitter > 0 {
+		j := p.Jitter
+		if j > 1 {
+			j = 1
+		}
+		d -= time.Duration(rand.Float64() * j * float64(d))
+	}
+	return d
+}
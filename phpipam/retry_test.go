@@ -0,0 +1,87 @@
+package phpipam
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRetryPolicyShouldRetry(t *testing.T) {
+	p := DefaultRetryPolicy()
+
+	cases := []struct {
+		Name       string
+		Method     string
+		Attempt    int
+		StatusCode int
+		Expected   bool
+	}{
+		{"GET 503", "GET", 1, 503, true},
+		{"GET 502, last attempt", "GET", 3, 502, false},
+		{"GET connection error", "GET", 1, 0, true},
+		{"GET 404", "GET", 1, 404, false},
+		{"DELETE 504", "DELETE", 2, 504, true},
+		{"POST 503", "POST", 1, 503, false},
+		{"PATCH connection error", "PATCH", 1, 0, false},
+	}
+
+	for _, tc := range cases {
+		if actual := p.ShouldRetry(tc.Method, tc.Attempt, tc.StatusCode); actual != tc.Expected {
+			t.Fatalf("%s: expected %t, got %t", tc.Name, tc.Expected, actual)
+		}
+	}
+}
+
+func TestRetryPolicyShouldRetryNonIdempotent(t *testing.T) {
+	p := DefaultRetryPolicy()
+	p.RetryNonIdempotent = true
+	p.RetryableStatusCodes = []int{500}
+
+	if !p.ShouldRetry("POST", 1, 500) {
+		t.Fatalf("Expected POST 500 to be retried")
+	}
+	if p.ShouldRetry("POST", 1, 503) {
+		t.Fatalf("Expected POST 503 to not be retried")
+	}
+}
+
+func TestRetryPolicyShouldRetryZeroValue(t *testing.T) {
+	var p RetryPolicy
+	if p.ShouldRetry("GET", 1, 503) {
+		t.Fatalf("Expected zero value policy to never retry")
+	}
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	p := RetryPolicy{
+		MinBackoff: 100 * time.Millisecond,
+		MaxBackoff: time.Second,
+	}
+
+	expected := []time.Duration{
+		100 * time.Millisecond,
+		200 * time.Millisecond,
+		400 * time.Millisecond,
+		800 * time.Millisecond,
+		time.Second,
+		time.Second,
+	}
+	for i, e := range expected {
+		if actual := p.Backoff(i + 1); actual != e {
+			t.Fatalf("Attempt %d: expected %s, got %s", i+1, e, actual)
+		}
+	}
+}
+
+func TestRetryPolicyBackoffJitter(t *testing.T) {
+	p := RetryPolicy{
+		MinBackoff: time.Second,
+		Jitter:     0.5,
+	}
+
+	for i := 0; i < 100; i++ {
+		actual := p.Backoff(1)
+		if actual < 500*time.Millisecond || actual > time.Second {
+			t.Fatalf("Expected backoff between 500ms and 1s, got %s", actual)
+		}
+	}
+}
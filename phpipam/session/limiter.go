@@ -0,0 +1,114 @@
+package session
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// rateLimiter is a simple token bucket rate limiter.
+type rateLimiter struct {
+	// Guards the fields below.
+	mu sync.Mutex
+
+	// The rate that tokens are added to the bucket, per second.
+	rate float64
+
+	// The size of the bucket.
+	burst float64
+
+	// The number of tokens currently in the bucket. This goes negative when
+	// callers are waiting on tokens.
+	tokens float64
+
+	// The last time tokens was updated.
+	last time.Time
+}
+
+// newRateLimiter returns a new rateLimiter with a full bucket.
+func newRateLimiter(rate float64, burst int) *rateLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+	return &rateLimiter{
+		rate:   rate,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+// wait blocks until a token is available or ctx is done. A token is reserved
+// up front so that waiters are served in order.
+func (l *rateLimiter) wait(ctx context.Context) error {
+	l.mu.Lock()
+	now := time.Now()
+	l.tokens += now.Sub(l.last).Seconds() * l.rate
+	if l.tokens > l.burst {
+		l.tokens = l.burst
+	}
+	l.last = now
+	l.tokens--
+	deficit := -l.tokens
+	l.mu.Unlock()
+
+	if deficit <= 0 {
+		return nil
+	}
+
+	t := time.NewTimer(time.Duration(deficit / l.rate * float64(time.Second)))
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		// Give the reserved token back.
+		l.mu.Lock()
+		l.tokens++
+		l.mu.Unlock()
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+// initLimits sets up the rate limiter and in-flight semaphore from the
+// session's configuration.
+func (s *Session) initLimits() {
+	if s.Config.RateLimit > 0 {
+		s.limiter = newRateLimiter(s.Config.RateLimit, s.Config.RateBurst)
+	}
+	if s.Config.MaxInFlight > 0 {
+		s.inFlight = make(chan struct{}, s.Config.MaxInFlight)
+	}
+}
+
+// Acquire blocks until a request can be sent under the session's rate limit
+// and in-flight request cap (see phpipam.Config.RateLimit and
+// phpipam.Config.MaxInFlight), or until ctx is done. On success, the returned
+// function must be called once the request is complete to release the
+// in-flight slot.
+//
+// The limits are set up from the session's configuration on first use, so
+// changes to the configuration after that have no effect.
+func (s *Session) Acquire(ctx context.Context) (release func(), err error) {
+	s.limitsOnce.Do(s.initLimits)
+
+	if s.limiter != nil {
+		if err := s.limiter.wait(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	if s.inFlight == nil {
+		return func() {}, nil
+	}
+
+	select {
+	case s.inFlight <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	var once sync.Once
+	return func() {
+		once.Do(func() { <-s.inFlight })
+	}, nil
+}
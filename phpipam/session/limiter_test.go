@@ -0,0 +1,114 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestAcquireNoLimits(t *testing.T) {
+	sess := NewSession(phpipamConfig())
+
+	for i := 0; i < 100; i++ {
+		release, err := sess.Acquire(context.Background())
+		if err != nil {
+			t.Fatalf("Bad: %s", err)
+		}
+		release()
+	}
+}
+
+func TestAcquireMaxInFlight(t *testing.T) {
+	cfg := phpipamConfig()
+	cfg.MaxInFlight = 3
+	sess := NewSession(cfg)
+
+	var current, max int32
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			release, err := sess.Acquire(context.Background())
+			if err != nil {
+				t.Errorf("Bad: %s", err)
+				return
+			}
+			defer release()
+			n := atomic.AddInt32(&current, 1)
+			for {
+				m := atomic.LoadInt32(&max)
+				if n <= m || atomic.CompareAndSwapInt32(&max, m, n) {
+					break
+				}
+			}
+			time.Sleep(5 * time.Millisecond)
+			atomic.AddInt32(&current, -1)
+		}()
+	}
+	wg.Wait()
+
+	if max > 3 {
+		t.Fatalf("Expected at most 3 requests in flight, got %d", max)
+	}
+}
+
+func TestAcquireMaxInFlightContextCancelled(t *testing.T) {
+	cfg := phpipamConfig()
+	cfg.MaxInFlight = 1
+	sess := NewSession(cfg)
+
+	release, err := sess.Acquire(context.Background())
+	if err != nil {
+		t.Fatalf("Bad: %s", err)
+	}
+	defer release()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := sess.Acquire(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected %s, got %v", context.DeadlineExceeded, err)
+	}
+}
+
+func TestAcquireRateLimit(t *testing.T) {
+	cfg := phpipamConfig()
+	cfg.RateLimit = 100
+	cfg.RateBurst = 2
+	sess := NewSession(cfg)
+
+	start := time.Now()
+	for i := 0; i < 7; i++ {
+		release, err := sess.Acquire(context.Background())
+		if err != nil {
+			t.Fatalf("Bad: %s", err)
+		}
+		release()
+	}
+
+	// The first 2 requests go out in a burst, the next 5 are spaced at 10ms.
+	if elapsed := time.Since(start); elapsed < 45*time.Millisecond {
+		t.Fatalf("Expected requests to be rate limited, took %s", elapsed)
+	}
+}
+
+func TestAcquireRateLimitContextCancelled(t *testing.T) {
+	cfg := phpipamConfig()
+	cfg.RateLimit = 1
+	sess := NewSession(cfg)
+
+	release, err := sess.Acquire(context.Background())
+	if err != nil {
+		t.Fatalf("Bad: %s", err)
+	}
+	release()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := sess.Acquire(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected %s, got %v", context.DeadlineExceeded, err)
+	}
+}
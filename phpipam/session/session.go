@@ -2,6 +2,12 @@
 package session
 
 import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
 	"github.com/imdario/mergo"
 	"github.com/paybyphone/phpipam-sdk-go/phpipam"
 )
@@ -9,10 +15,33 @@ import (
 // timeLayout represents the datetime format returned by the PHPIPAM api.
 const timeLayout = "2006-01-02 15:04:05"
 
+// defaultTokenRefreshSkew is the refresh skew used when
+// phpipam.Config.TokenRefreshSkew is zero.
+const defaultTokenRefreshSkew = time.Minute
+
 // Token represents a PHPIPAM session token.
 type Token struct {
 	// The token string.
 	String string `json:"token"`
+
+	// The expiry time of the token, as returned by the API.
+	Expires string `json:"expires,omitempty"`
+
+	// The local time that the token expires at, as worked out by
+	// Session.TokenDeadline when the token was received. The zero time means
+	// that the expiry time is unknown.
+	Deadline time.Time `json:"-"`
+}
+
+// ExpiresAt returns the local time that the token expires at, which is the
+// token's Deadline. The zero time is returned if the expiry time is unknown.
+//
+// Expires is not used, as PHPIPAM does not include a time zone in its
+// timestamps, and the server's time zone can differ from the local one. The
+// deadline is only reliable if phpipam.Config.ServerTimeZone is set, or if the
+// server runs in UTC.
+func (t Token) ExpiresAt() time.Time {
+	return t.Deadline
 }
 
 // Session represents a PHPIPAM session.
@@ -21,7 +50,31 @@ type Session struct {
 	Config phpipam.Config
 
 	// The session token.
+	//
+	// This can be set directly when setting up a session, but once the session
+	// is in use, it should only be accessed through CurrentToken and SetToken,
+	// as it may be updated concurrently by a login or token refresh.
 	Token Token
+
+	// Guards Token.
+	tokenMu sync.RWMutex
+
+	// The login currently in flight, if any, and its guard.
+	login   *loginCall
+	loginMu sync.Mutex
+
+	// The HTTP client shared by all requests in this session, built on first
+	// use by HTTPClient.
+	httpClient *http.Client
+
+	// Guards httpClient.
+	httpClientMu sync.Mutex
+
+	// The rate limiter and in-flight request semaphore for the session, set
+	// up on first use by Acquire.
+	limiter    *rateLimiter
+	inFlight   chan struct{}
+	limitsOnce sync.Once
 }
 
 // NewSession creates a new session based off supplied configs. It is up to the
@@ -37,3 +90,203 @@ func NewSession(configs ...phpipam.Config) *Session {
 
 	return s
 }
+
+// HTTPClient returns the HTTP client for this session. The client is built
+// from the session's configuration the first time this is called, and the
+// same client is returned on subsequent calls so that connections can be
+// reused across requests.
+//
+// Redirects are never followed by the returned client - this is true even if
+// a client has been supplied via Config.HTTPClient, in which case a shallow
+// copy of that client is used.
+func (s *Session) HTTPClient() (*http.Client, error) {
+	s.httpClientMu.Lock()
+	defer s.httpClientMu.Unlock()
+
+	if s.httpClient != nil {
+		return s.httpClient, nil
+	}
+
+	var c http.Client
+	switch {
+	case s.Config.HTTPClient != nil:
+		c = *s.Config.HTTPClient
+	case s.Config.Transport != nil:
+		c.Transport = s.Config.Transport
+		c.Timeout = s.Config.Timeout
+	default:
+		tlsConfig, err := s.Config.TLSConfig()
+		if err != nil {
+			return nil, err
+		}
+		if tlsConfig != nil {
+			t := http.DefaultTransport.(*http.Transport).Clone()
+			t.TLSClientConfig = tlsConfig
+			c.Transport = t
+		}
+		c.Timeout = s.Config.Timeout
+	}
+
+	c.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+
+	s.httpClient = &c
+	return s.httpClient, nil
+}
+
+// CurrentToken returns the session's current token.
+func (s *Session) CurrentToken() Token {
+	s.tokenMu.RLock()
+	defer s.tokenMu.RUnlock()
+	return s.Token
+}
+
+// SetToken replaces the session's token.
+func (s *Session) SetToken(t Token) {
+	s.tokenMu.Lock()
+	defer s.tokenMu.Unlock()
+	s.Token = t
+}
+
+// ExpiresAt returns the expiry time of the session's current token. The zero
+// time is returned if there is no token or its expiry time is unknown.
+func (s *Session) ExpiresAt() time.Time {
+	return s.CurrentToken().ExpiresAt()
+}
+
+// tokenRefreshSkew returns the refresh skew from the session's configuration,
+// with the default applied. A negative value means that proactive refresh is
+// disabled.
+func (s *Session) tokenRefreshSkew() time.Duration {
+	if s.Config.TokenRefreshSkew == 0 {
+		return defaultTokenRefreshSkew
+	}
+	return s.Config.TokenRefreshSkew
+}
+
+// TokenDeadline works out the local time that a token with the expiry time
+// expires (as returned by the API) expires at, for use as Token.Deadline.
+// date is the Date header of the response that the token was received in, and
+// received is the local time the response was received.
+//
+// PHPIPAM does not include a time zone in its timestamps, so the lifetime of
+// the token is worked out by reading expires in phpipam.Config.ServerTimeZone
+// and comparing it to the server's Date header. This does not depend on the
+// local time zone or clock.
+//
+// If ServerTimeZone is not set, expires is read as UTC, which is only
+// reliable if the server runs in UTC. If the lifetime comes out to within the
+// refresh skew, such as when the server's time zone is behind UTC, the expiry
+// time is treated as unknown so that the token is only refreshed once it is
+// rejected by the API, rather than on every request.
+//
+// The zero time is returned if the expiry time is unknown.
+func (s *Session) TokenDeadline(expires, date string, received time.Time) time.Time {
+	if expires == "" || date == "" {
+		return time.Time{}
+	}
+	loc := s.Config.ServerTimeZone
+	if loc == nil {
+		loc = time.UTC
+	}
+	e, err := time.ParseInLocation(timeLayout, expires, loc)
+	if err != nil {
+		return time.Time{}
+	}
+	now, err := http.ParseTime(date)
+	if err != nil {
+		return time.Time{}
+	}
+	lifetime := e.Sub(now)
+	if skew := s.tokenRefreshSkew(); lifetime <= 0 || (skew > 0 && lifetime <= skew) {
+		return time.Time{}
+	}
+	return received.Add(lifetime)
+}
+
+// TokenExpiring returns true if the session's current token expires within
+// the refresh skew set in phpipam.Config.TokenRefreshSkew, and hence should be
+// refreshed before it is used again. false is always returned if the token's
+// expiry time is unknown, or if proactive refresh is disabled.
+func (s *Session) TokenExpiring() bool {
+	skew := s.tokenRefreshSkew()
+	if skew < 0 {
+		return false
+	}
+	e := s.ExpiresAt()
+	if e.IsZero() {
+		return false
+	}
+	return time.Now().Add(skew).After(e)
+}
+
+// CompareAndSetToken replaces the session's token with new, but only if the
+// current token is still old. Returns true if the token was replaced.
+func (s *Session) CompareAndSetToken(old, new Token) bool {
+	s.tokenMu.Lock()
+	defer s.tokenMu.Unlock()
+	if s.Token != old {
+		return false
+	}
+	s.Token = new
+	return true
+}
+
+// loginCall represents a login that is in flight.
+type loginCall struct {
+	// Closed when the login completes.
+	done chan struct{}
+
+	// The result of the login.
+	err error
+}
+
+// Refresh obtains a new token for the session by calling login, and stores
+// it. stale should be the token that was found to be missing or expired.
+//
+// Concurrent calls are collapsed into a single call to login, the result of
+// which is shared by all callers. If the session's token has already been
+// replaced with one other than stale by the time Refresh is called, login is
+// not called at all and the new token is used as-is.
+func (s *Session) Refresh(ctx context.Context, stale Token, login func(context.Context) (Token, error)) error {
+	for {
+		s.loginMu.Lock()
+		if s.CurrentToken() != stale {
+			s.loginMu.Unlock()
+			return nil
+		}
+		if c := s.login; c != nil {
+			s.loginMu.Unlock()
+			select {
+			case <-c.done:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+			// If the login failed only because the context of the caller that
+			// started it ended, try again with our own.
+			if (errors.Is(c.err, context.Canceled) || errors.Is(c.err, context.DeadlineExceeded)) && ctx.Err() == nil {
+				continue
+			}
+			return c.err
+		}
+
+		c := &loginCall{
+			done: make(chan struct{}),
+		}
+		s.login = c
+		s.loginMu.Unlock()
+
+		t, err := login(ctx)
+		if err == nil {
+			s.SetToken(t)
+		}
+		c.err = err
+
+		s.loginMu.Lock()
+		s.login = nil
+		s.loginMu.Unlock()
+		close(c.done)
+		return err
+	}
+}
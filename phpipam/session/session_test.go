@@ -1,8 +1,14 @@
 package session
 
 import (
+	"context"
+	"errors"
+	"net/http"
 	"reflect"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/paybyphone/phpipam-sdk-go/phpipam"
 )
@@ -38,3 +44,200 @@ func TestNewSession(t *testing.T) {
 		t.Fatalf("Expected session to be %#v, got %#v", expected, actual)
 	}
 }
+
+func TestHTTPClientReused(t *testing.T) {
+	sess := NewSession(phpipamConfig())
+
+	first, err := sess.HTTPClient()
+	if err != nil {
+		t.Fatalf("Bad: %s", err)
+	}
+	second, err := sess.HTTPClient()
+	if err != nil {
+		t.Fatalf("Bad: %s", err)
+	}
+
+	if first != second {
+		t.Fatalf("Expected the same client to be returned, got %p and %p", first, second)
+	}
+}
+
+func TestHTTPClientCustomClient(t *testing.T) {
+	cfg := phpipamConfig()
+	custom := &http.Client{
+		Timeout: 5 * time.Second,
+	}
+	cfg.HTTPClient = custom
+	sess := NewSession(cfg)
+
+	actual, err := sess.HTTPClient()
+	if err != nil {
+		t.Fatalf("Bad: %s", err)
+	}
+
+	if actual.Timeout != custom.Timeout {
+		t.Fatalf("Expected timeout to be %s, got %s", custom.Timeout, actual.Timeout)
+	}
+	if actual.CheckRedirect == nil {
+		t.Fatalf("Expected redirects to be disabled on the session client")
+	}
+	if custom.CheckRedirect != nil {
+		t.Fatalf("Expected the supplied client to be left untouched")
+	}
+}
+
+func TestHTTPClientTransport(t *testing.T) {
+	cfg := phpipamConfig()
+	transport := &http.Transport{}
+	cfg.Transport = transport
+	cfg.Timeout = 10 * time.Second
+	sess := NewSession(cfg)
+
+	actual, err := sess.HTTPClient()
+	if err != nil {
+		t.Fatalf("Bad: %s", err)
+	}
+
+	if actual.Transport != transport {
+		t.Fatalf("Expected transport to be %#v, got %#v", transport, actual.Transport)
+	}
+	if actual.Timeout != cfg.Timeout {
+		t.Fatalf("Expected timeout to be %s, got %s", cfg.Timeout, actual.Timeout)
+	}
+}
+
+func TestHTTPClientTLSError(t *testing.T) {
+	cfg := phpipamConfig()
+	cfg.CACertFile = "/nonexistent/ca.pem"
+	sess := NewSession(cfg)
+
+	if _, err := sess.HTTPClient(); err == nil {
+		t.Fatalf("Expected error, got none")
+	}
+}
+
+func TestRefreshSingleFlight(t *testing.T) {
+	sess := fullSessionConfig()
+	stale := sess.CurrentToken()
+
+	var logins int32
+	login := func(ctx context.Context) (Token, error) {
+		atomic.AddInt32(&logins, 1)
+		time.Sleep(20 * time.Millisecond)
+		return Token{String: "newtoken"}, nil
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := sess.Refresh(context.Background(), stale, login); err != nil {
+				t.Errorf("Bad: %s", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if logins != 1 {
+		t.Fatalf("Expected 1 login, got %d", logins)
+	}
+
+	expected := Token{String: "newtoken"}
+	if actual := sess.CurrentToken(); actual != expected {
+		t.Fatalf("Expected token to be %#v, got %#v", expected, actual)
+	}
+}
+
+func TestRefreshAlreadyRefreshed(t *testing.T) {
+	sess := fullSessionConfig()
+	stale := Token{String: "expired"}
+
+	login := func(ctx context.Context) (Token, error) {
+		t.Fatalf("Login should not have been called")
+		return Token{}, nil
+	}
+
+	if err := sess.Refresh(context.Background(), stale, login); err != nil {
+		t.Fatalf("Bad: %s", err)
+	}
+}
+
+func TestRefreshError(t *testing.T) {
+	sess := fullSessionConfig()
+	expected := errors.New("login failed")
+
+	login := func(ctx context.Context) (Token, error) {
+		return Token{}, expected
+	}
+
+	if err := sess.Refresh(context.Background(), sess.CurrentToken(), login); err != expected {
+		t.Fatalf("Expected %s, got %v", expected, err)
+	}
+
+	if actual := sess.CurrentToken(); actual.String != "foobarbazboop" {
+		t.Fatalf("Expected token to be unchanged, got %#v", actual)
+	}
+}
+
+func TestTokenDeadline(t *testing.T) {
+	// The local time zone must not matter.
+	defer func(l *time.Location) { time.Local = l }(time.Local)
+	time.Local = time.FixedZone("UTC+14", 14*60*60)
+
+	received := time.Date(2017, 3, 3, 12, 0, 0, 0, time.UTC)
+	cases := []struct {
+		Name     string
+		Expires  string
+		Date     string
+		Skew     time.Duration
+		Zone     *time.Location
+		Expected time.Time
+	}{
+		{"server in UTC", "2017-03-03 06:00:00", "Fri, 03 Mar 2017 00:00:00 GMT", 0, nil, received.Add(6 * time.Hour)},
+		{"within default skew", "2017-03-03 00:00:30", "Fri, 03 Mar 2017 00:00:00 GMT", 0, nil, time.Time{}},
+		{"outside custom skew", "2017-03-03 00:00:30", "Fri, 03 Mar 2017 00:00:00 GMT", 10 * time.Second, nil, received.Add(30 * time.Second)},
+		{"server behind UTC", "2017-03-02 20:00:00", "Fri, 03 Mar 2017 00:00:00 GMT", 0, nil, time.Time{}},
+		{"skew disabled", "2017-03-03 00:00:30", "Fri, 03 Mar 2017 00:00:00 GMT", -1, nil, received.Add(30 * time.Second)},
+		{"server ahead of UTC with zone", "2017-03-03 08:00:00", "Fri, 03 Mar 2017 00:00:00 GMT", 0, time.FixedZone("UTC+2", 2*60*60), received.Add(6 * time.Hour)},
+		{"server behind UTC with zone", "2017-03-02 20:00:00", "Fri, 03 Mar 2017 00:00:00 GMT", 0, time.FixedZone("UTC-5", -5*60*60), received.Add(1 * time.Hour)},
+		{"no expiry", "", "Fri, 03 Mar 2017 00:00:00 GMT", 0, nil, time.Time{}},
+		{"no date", "2017-03-03 06:00:00", "", 0, nil, time.Time{}},
+		{"invalid expiry", "tomorrow", "Fri, 03 Mar 2017 00:00:00 GMT", 0, nil, time.Time{}},
+		{"invalid date", "2017-03-03 06:00:00", "today", 0, nil, time.Time{}},
+	}
+
+	for _, tc := range cases {
+		sess := fullSessionConfig()
+		sess.Config.TokenRefreshSkew = tc.Skew
+		sess.Config.ServerTimeZone = tc.Zone
+		if actual := sess.TokenDeadline(tc.Expires, tc.Date, received); !actual.Equal(tc.Expected) {
+			t.Fatalf("%s: expected %s, got %s", tc.Name, tc.Expected, actual)
+		}
+	}
+}
+
+func TestTokenExpiring(t *testing.T) {
+	cases := []struct {
+		Name     string
+		Deadline time.Time
+		Skew     time.Duration
+		Expected bool
+	}{
+		{"unknown expiry", time.Time{}, 0, false},
+		{"far future", time.Now().Add(time.Hour), 0, false},
+		{"within default skew", time.Now().Add(30 * time.Second), 0, true},
+		{"outside custom skew", time.Now().Add(30 * time.Second), 10 * time.Second, false},
+		{"already expired", time.Now().Add(-time.Hour), 0, true},
+		{"disabled", time.Now().Add(-time.Hour), -1, false},
+	}
+
+	for _, tc := range cases {
+		sess := fullSessionConfig()
+		sess.Token.Deadline = tc.Deadline
+		sess.Config.TokenRefreshSkew = tc.Skew
+		if actual := sess.TokenExpiring(); actual != tc.Expected {
+			t.Fatalf("%s: expected %t, got %t", tc.Name, tc.Expected, actual)
+		}
+	}
+}